@@ -5,12 +5,16 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	api "github.com/FantasyRL/go-mcp-demo/api/model/api"
 	"github.com/FantasyRL/go-mcp-demo/api/pack"
 	"github.com/FantasyRL/go-mcp-demo/internal/host"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/stream_buffer"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/cloudwego/hertz/pkg/protocol/sse"
+	"strconv"
 )
 
 // Chat .
@@ -25,12 +29,27 @@ func Chat(ctx context.Context, c *app.RequestContext) {
 	}
 
 	resp := new(api.ChatResponse)
-	msg, err := host.NewHost(ctx, clientSet).Chat(1, req.Message)
+	h := host.NewHost(ctx, clientSet)
+	sessionID, err := h.OpenSession(ctx, req.GetSessionID(), req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	// 与流式对话共用 StreamBuffer 判断会话是否正在生成，同一会话同时只允许一轮
+	turn, err := clientSet.StreamBuffer.Start(sessionID, nil)
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	defer turn.Finish()
+	msg, reason, err := h.Chat(sessionID, req.Message)
 	if err != nil {
 		pack.RespError(c, err)
 		return
 	}
 	resp.Response = msg
+	resp.SessionID = sessionID
+	resp.FinishReason = reason
 	pack.RespData(c, resp)
 }
 
@@ -44,23 +63,349 @@ func ChatSSE(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
+	h := host.NewHost(ctx, clientSet)
+	sessionID, err := h.OpenSession(ctx, req.GetSessionID(), req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	streamChat(ctx, c, sessionID, req.LastEventID, func(ctx context.Context, emit func(string, any) error) error {
+		return host.NewHost(ctx, clientSet).StreamChatOpenAI(ctx, sessionID, req.Message, host.ChatOptions{}, emit)
+	})
+}
+
+// ChatStream .
+// @router /api/v1/chat/stream [POST]
+func ChatStream(ctx context.Context, c *app.RequestContext) {
+	var req api.ChatStreamRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	h := host.NewHost(ctx, clientSet)
+	sessionID, err := h.OpenSession(ctx, req.GetSessionID(), req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	opts := host.ChatOptions{
+		Model:       req.GetModel(),
+		Temperature: req.Temperature,
+		Tools:       req.Tools,
+		Attachments: pack.BuildAttachments(req.Attachments),
+		Resources:   pack.BuildResourceRefs(req.Resources),
+	}
+	streamChat(ctx, c, sessionID, req.LastEventID, func(ctx context.Context, emit func(string, any) error) error {
+		return host.NewHost(ctx, clientSet).StreamChatOpenAI(ctx, sessionID, req.Message, opts, emit)
+	})
+}
+
+// ChatWS .
+// @router /api/v1/chat/ws [GET]
+func ChatWS(ctx context.Context, c *app.RequestContext) {
+	var req api.ChatWSRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	h := host.NewHost(ctx, clientSet)
+	sessionID, err := h.OpenSession(ctx, req.GetSessionID(), req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	upgradeWS(c, func(conn *wsConn) {
+		serveChatWS(ctx, conn, sessionID)
+	})
+}
+
+// CreateSession .
+// @router /api/v1/sessions [POST]
+func CreateSession(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CreateSessionRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.CreateSessionResponse)
+	info, err := host.NewHost(ctx, clientSet).CreateSession(ctx, req.GetUserID(), req.GetTitle())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.Session = pack.BuildSession(info)
+	pack.RespData(c, resp)
+}
+
+// ListSessions .
+// @router /api/v1/sessions [GET]
+func ListSessions(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ListSessionsRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.ListSessionsResponse)
+	list, err := host.NewHost(ctx, clientSet).ListSessions(ctx, req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.Sessions = pack.BuildSessionList(list)
+	pack.RespData(c, resp)
+}
+
+// GetSession .
+// @router /api/v1/sessions/:session_id [GET]
+func GetSession(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.GetSessionRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.GetSessionResponse)
+	info, msgs, err := host.NewHost(ctx, clientSet).GetSession(ctx, req.SessionID, req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.Session = pack.BuildSession(info)
+	resp.Messages = pack.BuildSessionMessages(msgs)
+	pack.RespData(c, resp)
+}
+
+// RenameSession .
+// @router /api/v1/sessions/:session_id [PUT]
+func RenameSession(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.RenameSessionRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.RenameSessionResponse)
+	info, err := host.NewHost(ctx, clientSet).RenameSession(ctx, req.SessionID, req.GetUserID(), req.Title)
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.Session = pack.BuildSession(info)
+	pack.RespData(c, resp)
+}
+
+// DeleteSession .
+// @router /api/v1/sessions/:session_id [DELETE]
+func DeleteSession(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.DeleteSessionRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.DeleteSessionResponse)
+	if err = host.NewHost(ctx, clientSet).DeleteSession(ctx, req.SessionID, req.GetUserID()); err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.SessionID = req.SessionID
+	pack.RespData(c, resp)
+}
+
+// ClearSession .
+// @router /api/v1/sessions/:session_id/clear [POST]
+func ClearSession(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ClearSessionRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.ClearSessionResponse)
+	info, err := host.NewHost(ctx, clientSet).ClearSession(ctx, req.SessionID, req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.Session = pack.BuildSession(info)
+	pack.RespData(c, resp)
+}
+
+// ListApprovals .
+// @router /api/v1/sessions/:session_id/approvals [GET]
+func ListApprovals(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ListApprovalsRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.ListApprovalsResponse)
+	list, err := host.NewHost(ctx, clientSet).ListApprovals(ctx, req.SessionID, req.GetUserID())
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.Approvals = pack.BuildToolApprovals(list)
+	pack.RespData(c, resp)
+}
+
+// ResolveApproval .
+// @router /api/v1/sessions/:session_id/approvals [POST]
+func ResolveApproval(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ResolveApprovalRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.ResolveApprovalResponse)
+	if err = host.NewHost(ctx, clientSet).ResolveApproval(ctx, req.SessionID, req.GetUserID(), req.ToolCallID, req.Approved); err != nil {
+		pack.RespError(c, err)
+		return
+	}
+	resp.ToolCallID = req.ToolCallID
+	resp.Approved = req.Approved
+	pack.RespData(c, resp)
+}
+
+// writeSSE 将缓存的事件写出，事件名及 data 结构见 idl/api.thrift 中的 SSE*Event
+func writeSSE(w *sse.Writer) func(stream_buffer.Event) error {
+	return func(e stream_buffer.Event) error {
+		return w.WriteEvent(strconv.FormatInt(e.ID, 10), e.Name, e.Data)
+	}
+}
+
+// streamChat 以 SSE 推送一轮对话；lastEventID 非空时为断线重连，从其后继续推送仍缓存着的那一轮
+func streamChat(ctx context.Context, c *app.RequestContext, sessionID string, lastEventID *string,
+	run func(ctx context.Context, emit func(string, any) error) error,
+) {
+	// 会话ID需在写出首个事件前放入响应头
+	c.Response.Header.Set(constant.HeaderSessionID, sessionID)
+
+	if lastEventID != nil {
+		after, err := strconv.ParseInt(*lastEventID, 10, 64)
+		if err != nil {
+			c.String(consts.StatusBadRequest, "invalid Last-Event-ID: "+*lastEventID)
+			return
+		}
+		w := sse.NewWriter(c)
+		defer w.Close()
+		turn := clientSet.StreamBuffer.Find(sessionID, after)
+		if turn == nil {
+			data, _ := json.Marshal(map[string]any{"error": "stream expired"})
+			_ = w.WriteEvent("", constant.SSEEventError, data)
+			return
+		}
+		_ = turn.Subscribe(ctx, after, writeSSE(w))
+		return
+	}
+
+	turn, err := startTurn(ctx, sessionID, run)
+	if err != nil {
+		pack.RespError(c, err)
+		return
+	}
 	w := sse.NewWriter(c)
 	defer w.Close()
+	_ = turn.Subscribe(ctx, 0, writeSSE(w))
+}
 
-	emit := func(_ string, v any) error {
-		switch x := v.(type) {
-		case string: // 用于 [DONE]
-			return w.WriteEvent("", "", []byte(x))
-		case json.RawMessage:
-			return w.WriteEvent("", "", x)
+// startTurn 为会话开始新的一轮生成：生成与客户端连接解耦，客户端断开后继续生成并缓存事件等待重连，
+// 超时无人重连或被主动取消时结束（本轮记录为 cancelled，并推送 reason 为 cancelled 的 done 事件）
+func startTurn(ctx context.Context, sessionID string, run func(ctx context.Context, emit func(string, any) error) error) (*stream_buffer.Turn, error) {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	turn, err := clientSet.StreamBuffer.Start(sessionID, cancel)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go func() {
+		defer cancel()
+		defer turn.Finish()
+		err := run(runCtx, turn.Emit)
+		switch {
+		case err == nil:
+		case errors.Is(err, context.Canceled):
+			_ = turn.Emit(constant.SSEEventDone, map[string]any{"reason": "cancelled"})
 		default:
-			b, _ := json.Marshal(v)
-			return w.WriteEvent("", "", b)
+			_ = turn.Emit(constant.SSEEventError, map[string]any{"error": err.Error()})
 		}
+	}()
+	return turn, nil
+}
+
+// ListMCPServers .
+// @router /api/v1/admin/mcp/servers [GET]
+func ListMCPServers(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ListMCPServersRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.ListMCPServersResponse)
+	resp.Servers = pack.BuildMCPServerStatuses(host.NewHost(ctx, clientSet).MCPServers(ctx))
+	pack.RespData(c, resp)
+}
+
+// ListMCPResources .
+// @router /api/v1/mcp/resources [GET]
+func ListMCPResources(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ListMCPResourcesRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := new(api.ListMCPResourcesResponse)
+	resources, templates := host.NewHost(ctx, clientSet).MCPResources(ctx)
+	resp.Resources = pack.BuildMCPResources(resources)
+	resp.Templates = pack.BuildMCPResourceTemplates(templates)
+	pack.RespData(c, resp)
+}
+
+// ReadMCPResource .
+// @router /api/v1/mcp/resources/read [GET]
+func ReadMCPResource(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ReadMCPResourceRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
 	}
 
-	if err := host.NewHost(ctx, clientSet).StreamChatOpenAI(ctx, 1, req.Message, emit); err != nil {
-		_ = emit("error", map[string]any{"error": err.Error()})
+	resp := new(api.ReadMCPResourceResponse)
+	contents, err := host.NewHost(ctx, clientSet).ReadMCPResource(ctx, req.GetServer(), req.URI)
+	if err != nil {
+		pack.RespError(c, err)
 		return
 	}
+	resp.Contents = pack.BuildContents(contents)
+	pack.RespData(c, resp)
 }
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/pkg/base"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/conversation_store"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/stream_buffer"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+)
+
+func TestChat(t *testing.T) {
+	Convey("Test chat", t, func() {
+		prev := clientSet
+		clientSet = &base.ClientSet{
+			ConversationStore: conversation_store.NewMemoryStore(),
+			StreamBuffer:      stream_buffer.NewStreamBuffer(time.Minute, time.Minute),
+		}
+		Reset(func() { clientSet = prev })
+
+		Convey("session busy", func() {
+			turn, err := clientSet.StreamBuffer.Start("s1", nil)
+			So(err, ShouldBeNil)
+			defer turn.Finish()
+
+			c := app.NewContext(0)
+			c.Request.SetMethod("POST")
+			c.Request.SetRequestURI("/api/v1/chat?message=hi&session_id=s1")
+			Chat(context.Background(), c)
+			So(string(c.Response.Body()), ShouldContainSubstring, `"code":"`+strconv.FormatInt(errno.SessionBusy.ErrorCode, 10)+`"`)
+		})
+	})
+}
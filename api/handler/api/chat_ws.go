@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	api "github.com/FantasyRL/go-mcp-demo/api/model/api"
+	"github.com/FantasyRL/go-mcp-demo/api/pack"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/internal/host"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/stream_buffer"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/gobwas/ws"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// wsAcceptGUID RFC 6455 握手时拼接在 Sec-WebSocket-Key 之后的固定值
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var errWSMessageTooBig = errors.New("websocket message too big")
+
+// wsEvent 服务端推送的消息，event/data 与 SSE 事件一致；id 仅对话事件携带，可用于 SSE 的 Last-Event-ID 续传
+type wsEvent struct {
+	ID    int64           `json:"id,omitempty"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// upgradeWS 校验握手请求并返回 101，连接在 handler 返回后交给 serve 处理；
+// 超过 WSReadTimeout 未收到客户端的任何帧时读取失败，连接随之关闭
+func upgradeWS(c *app.RequestContext, serve func(conn *wsConn)) {
+	key := string(c.Request.Header.Peek("Sec-WebSocket-Key"))
+	if !strings.EqualFold(string(c.Request.Header.Peek("Upgrade")), "websocket") || key == "" ||
+		string(c.Request.Header.Peek("Sec-WebSocket-Version")) != "13" {
+		c.String(consts.StatusBadRequest, "websocket handshake expected")
+		return
+	}
+	if !wsOriginAllowed(c) {
+		c.String(consts.StatusForbidden, "websocket origin not allowed")
+		return
+	}
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	c.SetStatusCode(consts.StatusSwitchingProtocols)
+	c.Response.Header.Set("Upgrade", "websocket")
+	c.Response.Header.Set("Connection", "Upgrade")
+	c.Response.Header.Set("Sec-WebSocket-Accept", base64.StdEncoding.EncodeToString(sum[:]))
+	c.Hijack(func(conn network.Conn) {
+		_ = conn.SetReadTimeout(constant.WSReadTimeout)
+		serve(&wsConn{conn: conn})
+	})
+}
+
+// wsOriginAllowed 握手请求的 Origin 是否允许：不带 Origin、与请求同源或在 websocket.allowed_origins 中
+func wsOriginAllowed(c *app.RequestContext) bool {
+	origin := string(c.Request.Header.Peek("Origin"))
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, string(c.Host())) {
+		return true
+	}
+	if config.WebSocket == nil {
+		return false
+	}
+	for _, o := range config.WebSocket.AllowedOrigins {
+		if o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// wsConn 服务端 WebSocket 连接，写操作加锁以便对话事件与控制消息的回复并发写入
+type wsConn struct {
+	conn network.Conn
+	mu   sync.Mutex
+}
+
+func (w *wsConn) writeFrame(f ws.Frame) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := ws.WriteFrame(w.conn, f); err != nil {
+		return err
+	}
+	return w.conn.Flush()
+}
+
+func (w *wsConn) writeEvent(id int64, event string, data []byte) error {
+	b, err := json.Marshal(wsEvent{ID: id, Event: event, Data: data})
+	if err != nil {
+		return err
+	}
+	return w.writeFrame(ws.NewTextFrame(b))
+}
+
+func (w *wsConn) writeError(err error) error {
+	data, _ := json.Marshal(map[string]any{"error": err.Error()})
+	return w.writeEvent(0, constant.SSEEventError, data)
+}
+
+// readMessage 读取一条完整的文本消息，自动应答 ping 与 close；连接关闭时返回 io.EOF
+// 不符合协议的帧（未加掩码、保留位非零、控制帧分片等）以 1002 关闭连接
+func (w *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	state := ws.StateServerSide
+	for {
+		h, err := ws.ReadHeader(w.conn)
+		if err != nil {
+			return nil, err
+		}
+		if err := ws.CheckHeader(h, state); err != nil {
+			_ = w.writeFrame(ws.NewCloseFrame(ws.NewCloseFrameBody(ws.StatusProtocolError, err.Error())))
+			return nil, err
+		}
+		if h.Length > constant.WSMaxMessageSize || int64(len(msg))+h.Length > constant.WSMaxMessageSize {
+			_ = w.writeFrame(ws.NewCloseFrame(ws.NewCloseFrameBody(ws.StatusMessageTooBig, "")))
+			return nil, errWSMessageTooBig
+		}
+		payload := make([]byte, h.Length)
+		if _, err := io.ReadFull(w.conn, payload); err != nil {
+			return nil, err
+		}
+		ws.Cipher(payload, h.Mask, 0)
+		switch h.OpCode {
+		case ws.OpPing:
+			if err := w.writeFrame(ws.NewPongFrame(payload)); err != nil {
+				return nil, err
+			}
+			continue
+		case ws.OpPong:
+			continue
+		case ws.OpClose:
+			_ = w.writeFrame(ws.NewCloseFrame(ws.NewCloseFrameBody(ws.StatusNormalClosure, "")))
+			return nil, io.EOF
+		}
+		msg = append(msg, payload...)
+		if h.Fin {
+			return msg, nil
+		}
+		state = state.Set(ws.StateFragmented)
+	}
+}
+
+// keepAlive 每隔 WSPingInterval 发送一次 ping，客户端回复的 pong 使读取不会超时；ctx 结束或写入失败时返回
+func (w *wsConn) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(constant.WSPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.writeFrame(ws.NewPingFrame(nil)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// serveChatWS 处理一条对话 WebSocket 连接：
+// - user_message 依次开始新的一轮（正在生成时排队），事件与 SSE 共用 StreamBuffer，断开后可通过 SSE 续传
+// - cancel / approve_tool / deny_tool 立即作用于会话中正在进行的一轮
+func serveChatWS(ctx context.Context, conn *wsConn, sessionID string) {
+	data, _ := json.Marshal(map[string]any{"session_id": sessionID})
+	if err := conn.writeEvent(0, constant.WSEventSession, data); err != nil {
+		return
+	}
+
+	connCtx, closeConn := context.WithCancel(context.WithoutCancel(ctx))
+	go conn.keepAlive(connCtx)
+	messages := make(chan *api.WSClientFrame, constant.WSMaxQueuedMessages)
+	runnerDone := make(chan struct{})
+	go func() {
+		defer close(runnerDone)
+		for frame := range messages {
+			if connCtx.Err() != nil {
+				return
+			}
+			runWSTurn(connCtx, conn, sessionID, frame)
+		}
+	}()
+	// 连接断开：停止转发与排队，正在进行的一轮交由 StreamBuffer 等待重连
+	defer func() {
+		closeConn()
+		close(messages)
+		<-runnerDone
+	}()
+
+	for {
+		msg, err := conn.readMessage()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				logger.Infof("[ws] session %s read: %v", sessionID, err)
+			}
+			return
+		}
+		var frame api.WSClientFrame
+		if err := json.Unmarshal(msg, &frame); err != nil {
+			_ = conn.writeError(err)
+			continue
+		}
+		switch frame.Type {
+		case constant.WSFrameUserMessage:
+			select {
+			case messages <- &frame:
+			default:
+				_ = conn.writeError(errors.New("too many queued messages"))
+			}
+		case constant.WSFrameCancel:
+			if turn := clientSet.StreamBuffer.Running(sessionID); turn != nil {
+				turn.Cancel()
+			}
+		case constant.WSFrameApproveTool, constant.WSFrameDenyTool:
+			approved := frame.Type == constant.WSFrameApproveTool
+			if err := clientSet.ToolApprovals.Resolve(sessionID, frame.GetToolCallID(), approved); err != nil {
+				_ = conn.writeError(err)
+			}
+		default:
+			_ = conn.writeError(errors.New("unknown message type: " + frame.Type))
+		}
+	}
+}
+
+// runWSTurn 开始一轮对话并把事件转发到连接，直到本轮结束或连接断开
+func runWSTurn(ctx context.Context, conn *wsConn, sessionID string, frame *api.WSClientFrame) {
+	opts := host.ChatOptions{
+		Model:           frame.GetModel(),
+		Temperature:     frame.Temperature,
+		Tools:           frame.Tools,
+		Attachments:     pack.BuildAttachments(frame.Attachments),
+		Resources:       pack.BuildResourceRefs(frame.Resources),
+		RequireApproval: frame.GetRequireApproval(),
+	}
+	turn, err := startTurn(ctx, sessionID, func(ctx context.Context, emit func(string, any) error) error {
+		return host.NewHost(ctx, clientSet).StreamChatOpenAI(ctx, sessionID, frame.GetMessage(), opts, emit)
+	})
+	if err != nil {
+		_ = conn.writeError(err)
+		return
+	}
+	_ = turn.Subscribe(ctx, 0, func(e stream_buffer.Event) error {
+		return conn.writeEvent(e.ID, e.Name, e.Data)
+	})
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/gobwas/ws"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+)
+
+// testConn 从 r 读取客户端发送的帧，写出的内容记录在 w 中
+type testConn struct {
+	*mock.Conn
+	r io.Reader
+	w bytes.Buffer
+}
+
+func (c *testConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *testConn) Write(b []byte) (int, error) {
+	return c.w.Write(b)
+}
+
+func (c *testConn) Flush() error {
+	return nil
+}
+
+// newTestConn 返回依次读到 frames 的连接
+func newTestConn(frames ...ws.Frame) *testConn {
+	var b bytes.Buffer
+	for _, f := range frames {
+		_ = ws.WriteFrame(&b, f)
+	}
+	return &testConn{Conn: mock.NewConn(""), r: &b}
+}
+
+// serverFrame 读取服务端写出的第一帧
+func serverFrame(conn *testConn) ws.Frame {
+	f, err := ws.ReadFrame(&conn.w)
+	So(err, ShouldBeNil)
+	return f
+}
+
+func TestChatWS(t *testing.T) {
+	Convey("Test chat websocket", t, func() {
+		Convey("masked frames", func() {
+			conn := newTestConn(
+				ws.MaskFrameInPlace(ws.NewPingFrame([]byte("p"))),
+				ws.MaskFrameInPlace(ws.NewFrame(ws.OpText, false, []byte("hel"))),
+				ws.MaskFrameInPlace(ws.NewFrame(ws.OpContinuation, true, []byte("lo"))),
+			)
+			msg, err := (&wsConn{conn: conn}).readMessage()
+			So(err, ShouldBeNil)
+			So(string(msg), ShouldEqual, "hello")
+			pong := serverFrame(conn)
+			So(pong.Header.OpCode, ShouldEqual, ws.OpPong)
+			So(string(pong.Payload), ShouldEqual, "p")
+		})
+
+		Convey("unmasked frames are rejected", func() {
+			conn := newTestConn(ws.NewTextFrame([]byte("hello")))
+			_, err := (&wsConn{conn: conn}).readMessage()
+			So(err, ShouldEqual, ws.ErrProtocolMaskRequired)
+			closeFrame := serverFrame(conn)
+			So(closeFrame.Header.OpCode, ShouldEqual, ws.OpClose)
+			code, _ := ws.ParseCloseFrameData(closeFrame.Payload)
+			So(code, ShouldEqual, ws.StatusProtocolError)
+		})
+
+		Convey("origin", func() {
+			cfg := new(config.Config)
+			cfg.WebSocket.AllowedOrigins = []string{"https://app.example.com"}
+			config.WebSocket = &cfg.WebSocket
+			Reset(func() { config.WebSocket = nil })
+
+			allowed := func(origin string) bool {
+				c := app.NewContext(0)
+				c.Request.SetHost("127.0.0.1:10001")
+				if origin != "" {
+					c.Request.Header.Set("Origin", origin)
+				}
+				return wsOriginAllowed(c)
+			}
+			So(allowed(""), ShouldBeTrue)
+			So(allowed("http://127.0.0.1:10001"), ShouldBeTrue)
+			So(allowed("https://app.example.com"), ShouldBeTrue)
+			So(allowed("https://evil.example.com"), ShouldBeFalse)
+
+			cfg.WebSocket.AllowedOrigins = []string{"*"}
+			So(allowed("https://evil.example.com"), ShouldBeTrue)
+		})
+	})
+}
@@ -7,5 +7,5 @@ import (
 var clientSet *base.ClientSet
 
 func Init() {
-	clientSet = base.NewClientSet(base.WithMCPClient(), base.WithAiProviderClient())
+	clientSet = base.NewClientSet(base.WithMCPClient(), base.WithAiProviderClient(), base.WithConversationStore(), base.WithStreamBuffer(), base.WithToolApprovals(), base.WithToolCache())
 }
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/FantasyRL/go-mcp-demo/api/pack"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/internal/host"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/sse"
+	"github.com/google/uuid"
+	openai "github.com/openai/openai-go/v2"
+	"slices"
+	"time"
+)
+
+// ChatCompletions OpenAI 兼容的对话接口，请求中的 messages 即完整上下文（不使用会话存储），
+// MCP 工具由 host 在服务端执行，调用方只会收到最终回复
+// @router /v1/chat/completions [POST]
+func ChatCompletions(ctx context.Context, c *app.RequestContext) {
+	var req openai.ChatCompletionNewParams
+	if err := json.Unmarshal(c.Request.Body(), &req); err != nil {
+		pack.RespOpenAIError(c, errno.Errorf(errno.ParamFormatCode, "invalid request body: %v", err))
+		return
+	}
+	// stream 不在 ChatCompletionNewParams 中（由 SDK 按调用方式设置），单独解析
+	var streamReq struct {
+		Stream bool `json:"stream"`
+	}
+	_ = json.Unmarshal(c.Request.Body(), &streamReq)
+
+	msgs, err := host.FromOpenAIMessages(req.Messages)
+	if err != nil {
+		pack.RespOpenAIError(c, err)
+		return
+	}
+	if len(msgs) == 0 {
+		pack.RespOpenAIError(c, errno.Errorf(errno.ParamMissingCode, "messages is required"))
+		return
+	}
+	opts := host.ChatOptions{Model: string(req.Model)}
+	if req.Temperature.Valid() {
+		t := req.Temperature.Value
+		opts.Temperature = &t
+	}
+	model := opts.Model
+	if model == "" {
+		model = config.AiProvider.Model
+	}
+
+	completion := pack.OpenAIChatCompletion{
+		ID:      "chatcmpl-" + uuid.NewString(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+	}
+	h := host.NewHost(ctx, clientSet)
+
+	if !streamReq.Stream {
+		reply, reason, err := h.ChatCompletion(ctx, msgs, opts, func(string, any) error { return nil })
+		if err != nil {
+			pack.RespOpenAIError(c, err)
+			return
+		}
+		completion.Choices = []pack.OpenAIChoice{{
+			Message:      &pack.OpenAIMessage{Role: "assistant", Content: reply},
+			FinishReason: pack.BuildOpenAIFinishReason(reason),
+			FinishDetail: pack.BuildOpenAIFinishDetail(reason),
+		}}
+		c.JSON(consts.StatusOK, completion)
+		return
+	}
+
+	// 流式：只转发文本增量，工具调用过程对调用方透明
+	completion.Object = "chat.completion.chunk"
+	w := sse.NewWriter(c)
+	defer w.Close()
+	writeChunk := func(delta *pack.OpenAIMessage, finishReason *string, finishDetail string) error {
+		completion.Choices = []pack.OpenAIChoice{{Delta: delta, FinishReason: finishReason, FinishDetail: finishDetail}}
+		data, _ := json.Marshal(completion)
+		return w.WriteEvent("", "", data)
+	}
+
+	if err := writeChunk(&pack.OpenAIMessage{Role: "assistant"}, nil, ""); err != nil {
+		return
+	}
+	_, reason, err := h.ChatCompletion(ctx, msgs, opts, func(event string, v any) error {
+		if event != constant.SSEEventDelta {
+			return nil
+		}
+		data, _ := v.(map[string]any)
+		text, _ := data["text"].(string)
+		return writeChunk(&pack.OpenAIMessage{Content: text}, nil, "")
+	})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			_, body := pack.BuildOpenAIError(err)
+			data, _ := json.Marshal(body)
+			_ = w.WriteEvent("", "", data)
+		}
+		return
+	}
+	_ = writeChunk(&pack.OpenAIMessage{}, pack.BuildOpenAIFinishReason(reason), pack.BuildOpenAIFinishDetail(reason))
+	_ = w.WriteEvent("", "", []byte("[DONE]"))
+}
+
+// ListModels OpenAI 兼容的模型列表：配置的模型以及 ai_provider.context.models 中声明的模型
+// @router /v1/models [GET]
+func ListModels(ctx context.Context, c *app.RequestContext) {
+	models := []string{config.AiProvider.Model}
+	for _, m := range config.AiProvider.Context.Models {
+		if !slices.Contains(models, m.Name) {
+			models = append(models, m.Name)
+		}
+	}
+	c.JSON(consts.StatusOK, pack.BuildOpenAIModelList(models, config.Server.Name))
+}
@@ -0,0 +1,29 @@
+package pack
+
+import (
+	"github.com/FantasyRL/go-mcp-demo/api/model/model"
+	"github.com/FantasyRL/go-mcp-demo/internal/host"
+)
+
+func BuildAttachments(list []*model.Attachment) []host.Attachment {
+	out := make([]host.Attachment, 0, len(list))
+	for _, a := range list {
+		out = append(out, host.Attachment{
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Content:     a.Content,
+		})
+	}
+	return out
+}
+
+func BuildResourceRefs(list []*model.ResourceRef) []host.ResourceRef {
+	out := make([]host.ResourceRef, 0, len(list))
+	for _, r := range list {
+		out = append(out, host.ResourceRef{
+			Server: r.GetServer(),
+			URI:    r.URI,
+		})
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package pack
+
+import (
+	"github.com/FantasyRL/go-mcp-demo/api/model/model"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+)
+
+func BuildMCPServerStatuses(list []mcp_client.ServerStatus) []*model.MCPServerStatus {
+	out := make([]*model.MCPServerStatus, 0, len(list))
+	for _, s := range list {
+		status := &model.MCPServerStatus{
+			Name:      s.Name,
+			Transport: s.Transport,
+			Healthy:   s.Healthy,
+			Tools:     s.Tools,
+			CheckedAt: s.CheckedAt.UnixMilli(),
+		}
+		if status.Tools == nil {
+			status.Tools = []string{}
+		}
+		if s.Error != "" {
+			status.Error = &s.Error
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+func BuildMCPResources(list []mcp_client.Resource) []*model.MCPResource {
+	out := make([]*model.MCPResource, 0, len(list))
+	for _, r := range list {
+		res := &model.MCPResource{Server: r.Server, URI: r.URI, Name: r.Name}
+		if r.Description != "" {
+			res.Description = &r.Description
+		}
+		if r.MIMEType != "" {
+			res.MimeType = &r.MIMEType
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+func BuildMCPResourceTemplates(list []mcp_client.ResourceTemplate) []*model.MCPResourceTemplate {
+	out := make([]*model.MCPResourceTemplate, 0, len(list))
+	for _, t := range list {
+		tpl := &model.MCPResourceTemplate{Server: t.Server, URITemplate: t.URITemplate, Name: t.Name}
+		if t.Description != "" {
+			tpl.Description = &t.Description
+		}
+		if t.MIMEType != "" {
+			tpl.MimeType = &t.MIMEType
+		}
+		out = append(out, tpl)
+	}
+	return out
+}
+
+func BuildContents(list []mcp_client.Content) []*model.ToolResultContent {
+	out := make([]*model.ToolResultContent, 0, len(list))
+	for _, c := range list {
+		content := &model.ToolResultContent{Type: c.Type}
+		if c.Text != "" {
+			content.Text = &c.Text
+		}
+		if c.MIMEType != "" {
+			content.MimeType = &c.MIMEType
+		}
+		if c.Data != "" {
+			content.Data = &c.Data
+		}
+		if c.URI != "" {
+			content.URI = &c.URI
+		}
+		out = append(out, content)
+	}
+	return out
+}
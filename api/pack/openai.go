@@ -0,0 +1,106 @@
+package pack
+
+import (
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"strconv"
+)
+
+// 以下为 OpenAI 兼容接口（/v1/*）的响应结构，字段与 OpenAI Chat Completions 保持一致
+
+type OpenAIMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+type OpenAIChoice struct {
+	Index        int            `json:"index"`
+	Message      *OpenAIMessage `json:"message,omitempty"`
+	Delta        *OpenAIMessage `json:"delta,omitempty"`
+	FinishReason *string        `json:"finish_reason"`
+	// FinishDetail 非标准字段，finish_reason 无法区分的结束原因（tool_round_limit、no_tool_details），正常结束时省略
+	FinishDetail string `json:"x_finish_detail,omitempty"`
+}
+
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type OpenAIChatCompletion struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"` // chat.completion | chat.completion.chunk
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   *OpenAIUsage   `json:"usage,omitempty"`
+}
+
+type OpenAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
+}
+
+type openAIErrorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+}
+
+type OpenAIError struct {
+	Error openAIErrorBody `json:"error"`
+}
+
+// BuildOpenAIFinishReason 将 host 的结束原因转换为 OpenAI 的 finish_reason（只能取 OpenAI 定义的值）：
+// 达到工具调用轮数上限时没有最终回答，对应 length，其余对应 stop；具体原因见 BuildOpenAIFinishDetail
+func BuildOpenAIFinishReason(reason string) *string {
+	r := "stop"
+	if reason == "tool_round_limit" {
+		r = "length"
+	}
+	return &r
+}
+
+// BuildOpenAIFinishDetail 返回 x_finish_detail：非 completed 时为 host 的结束原因，completed 时为空
+func BuildOpenAIFinishDetail(reason string) string {
+	if reason == "completed" {
+		return ""
+	}
+	return reason
+}
+
+func BuildOpenAIModelList(models []string, ownedBy string) *OpenAIModelList {
+	list := &OpenAIModelList{Object: "list", Data: make([]OpenAIModel, 0, len(models))}
+	for _, m := range models {
+		list.Data = append(list.Data, OpenAIModel{ID: m, Object: "model", OwnedBy: ownedBy})
+	}
+	return list
+}
+
+// BuildOpenAIError 将错误转换为 OpenAI 的错误格式，参数类错误对应 400，其余对应 500
+func BuildOpenAIError(err error) (int, *OpenAIError) {
+	Errno := errno.ConvertErr(err)
+	status, typ := consts.StatusInternalServerError, "server_error"
+	if Errno.ErrorCode >= errno.ParamErrorCode && Errno.ErrorCode < errno.AuthErrorCode {
+		status, typ = consts.StatusBadRequest, "invalid_request_error"
+	}
+	return status, &OpenAIError{Error: openAIErrorBody{
+		Message: Errno.ErrorMsg,
+		Type:    typ,
+		Code:    strconv.FormatInt(Errno.ErrorCode, 10),
+	}}
+}
+
+// RespOpenAIError OpenAI 兼容接口使用 OpenAI 的错误格式
+func RespOpenAIError(c *app.RequestContext, err error) {
+	c.JSON(BuildOpenAIError(err))
+}
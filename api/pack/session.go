@@ -0,0 +1,69 @@
+package pack
+
+import (
+	"github.com/FantasyRL/go-mcp-demo/api/model/model"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/conversation_store"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_approval"
+)
+
+func BuildSession(c *conversation_store.Conversation) *model.Session {
+	return &model.Session{
+		SessionID:    c.ID,
+		Title:        c.Title,
+		UserID:       c.UserID,
+		MessageCount: int64(c.MessageCount),
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+}
+
+func BuildSessionList(list []conversation_store.Conversation) []*model.Session {
+	out := make([]*model.Session, 0, len(list))
+	for i := range list {
+		out = append(out, BuildSession(&list[i]))
+	}
+	return out
+}
+
+func BuildSessionMessages(msgs []ai_provider.Message) []*model.SessionMessage {
+	out := make([]*model.SessionMessage, 0, len(msgs))
+	for _, m := range msgs {
+		sm := &model.SessionMessage{Role: m.Role, Content: m.Content}
+		for _, tc := range m.ToolCalls {
+			sm.ToolCalls = append(sm.ToolCalls, &model.ToolCall{
+				ID:        tc.ID,
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.ArgumentsString(),
+			})
+		}
+		if m.ToolCallID != "" {
+			sm.ToolCallID = &m.ToolCallID
+		}
+		if m.ToolName != "" {
+			sm.ToolName = &m.ToolName
+		}
+		if m.Status != "" {
+			sm.Status = &m.Status
+		}
+		if m.ToolStatus != "" {
+			sm.ToolStatus = &m.ToolStatus
+		}
+		out = append(out, sm)
+	}
+	return out
+}
+
+func BuildToolApprovals(list []tool_approval.Request) []*model.ToolApproval {
+	out := make([]*model.ToolApproval, 0, len(list))
+	for _, r := range list {
+		out = append(out, &model.ToolApproval{
+			ToolCallID: r.ID,
+			Name:       r.Name,
+			Arguments:  r.Arguments,
+			CreatedAt:  r.CreatedAt.UnixMilli(),
+			ExpiresAt:  r.ExpiresAt.UnixMilli(),
+		})
+	}
+	return out
+}
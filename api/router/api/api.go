@@ -21,9 +21,34 @@ func Register(r *server.Hertz) {
 		_api := root.Group("/api", _apiMw()...)
 		{
 			_v1 := _api.Group("/v1", _v1Mw()...)
+			{
+				_admin := _v1.Group("/admin", _adminMw()...)
+				{
+					_mcp := _admin.Group("/mcp", _mcpMw()...)
+					_mcp.GET("/servers", append(_listmcpserversMw(), api.ListMCPServers)...)
+				}
+			}
+			{
+				_mcp0 := _v1.Group("/mcp", _mcp0Mw()...)
+				_mcp0.GET("/resources", append(_listmcpresourcesMw(), api.ListMCPResources)...)
+				_resources := _mcp0.Group("/resources", _resourcesMw()...)
+				_resources.GET("/read", append(_readmcpresourceMw(), api.ReadMCPResource)...)
+			}
 			_v1.POST("/chat", append(_chat0Mw(), api.Chat)...)
 			_chat := _v1.Group("/chat", _chatMw()...)
 			_chat.GET("/sse", append(_chatsseMw(), api.ChatSSE)...)
+			_chat.POST("/stream", append(_chatstreamMw(), api.ChatStream)...)
+			_chat.GET("/ws", append(_chatwsMw(), api.ChatWS)...)
+			_v1.GET("/sessions", append(_listsessionsMw(), api.ListSessions)...)
+			_v1.POST("/sessions", append(_createsessionMw(), api.CreateSession)...)
+			_sessions := _v1.Group("/sessions", _sessionsMw()...)
+			_sessions.DELETE("/:session_id", append(_deletesessionMw(), api.DeleteSession)...)
+			_sessions.GET("/:session_id", append(_getsessionMw(), api.GetSession)...)
+			_sessions.PUT("/:session_id", append(_renamesessionMw(), api.RenameSession)...)
+			_session_id := _sessions.Group("/:session_id", _session_idMw()...)
+			_session_id.GET("/approvals", append(_listapprovalsMw(), api.ListApprovals)...)
+			_session_id.POST("/approvals", append(_resolveapprovalMw(), api.ResolveApproval)...)
+			_session_id.POST("/clear", append(_clearsessionMw(), api.ClearSession)...)
 		}
 	}
 }
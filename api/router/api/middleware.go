@@ -33,3 +33,98 @@ func _chatsseMw() []app.HandlerFunc {
 	// your code...
 	return nil
 }
+
+func _listsessionsMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _createsessionMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _sessionsMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _deletesessionMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _getsessionMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _renamesessionMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _session_idMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _clearsessionMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _chatstreamMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _chatwsMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _listapprovalsMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _resolveapprovalMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _adminMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _mcpMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _listmcpserversMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _mcp0Mw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _listmcpresourcesMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _resourcesMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _readmcpresourceMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
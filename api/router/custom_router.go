@@ -1,8 +1,18 @@
 package router
 
 import (
+	api "github.com/FantasyRL/go-mcp-demo/api/handler/api"
+	"github.com/FantasyRL/go-mcp-demo/pkg/metrics"
 	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/adaptor"
 )
 
 func customizedRegister(r *server.Hertz) {
+	// OpenAI 兼容接口，请求/响应格式由 OpenAI 规范决定，不经过 IDL
+	v1 := r.Group("/v1")
+	v1.POST("/chat/completions", api.ChatCompletions)
+	v1.GET("/models", api.ListModels)
+
+	// Prometheus 抓取接口
+	r.GET("/metrics", adaptor.HertzHandler(metrics.Handler()))
 }
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/internal/host"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"os"
+	"strings"
+)
+
+var (
+	serviceName = "cli"
+	configPath  = flag.String("cfg", "config/config.yaml", "config file path")
+	sessionFlag = flag.String("session", "", "session id to resume, empty for a new session")
+	userFlag    = flag.String("user", "", "user id bound to the session")
+)
+
+// 工具结果在终端中最多展示的字符数
+const maxResultPreview = 300
+
+// pendingResources 通过 /attach 附带的资源，随下一条消息发送
+var pendingResources []host.ResourceRef
+
+// model 通过 /model 切换的模型，只作用于本次运行的对话，为空时使用 ai_provider.model
+var model string
+
+func init() {
+	flag.Parse()
+	config.Load(*configPath, serviceName)
+	logger.Init(serviceName, config.GetLoggerLevel())
+}
+
+func main() {
+	ctx := context.Background()
+	clientSet := base.NewClientSet(base.WithMCPClient(), base.WithAiProviderClient(), base.WithConversationStore(), base.WithToolApprovals(), base.WithToolCache())
+	defer clientSet.Close()
+
+	h := host.NewHost(ctx, clientSet)
+	sessionID, err := h.OpenSession(ctx, *sessionFlag, *userFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open session failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("model: %s  session: %s\n输入 /help 查看命令，/exit 退出\n", config.AiProvider.Model, sessionID)
+
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Print("> ")
+		if !in.Scan() {
+			fmt.Println()
+			return
+		}
+		line := strings.TrimSpace(in.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "/") {
+			if quit := command(ctx, h, clientSet, sessionID, line); quit {
+				return
+			}
+			continue
+		}
+		if err := chat(ctx, h, in, sessionID, line); err != nil {
+			fmt.Fprintf(os.Stderr, "\n[error] %v\n", err)
+		}
+	}
+}
+
+// chat 按配置的模式选择对话链路，边生成边输出；需要审批的工具调用在终端询问
+func chat(ctx context.Context, h *host.Host, in *bufio.Scanner, sessionID, msg string) error {
+	emit := func(event string, v any) error {
+		if event == constant.SSEEventApprovalRequired {
+			approve(ctx, h, in, sessionID, v)
+			return nil
+		}
+		return render(event, v)
+	}
+	opts := host.ChatOptions{Model: model, Resources: pendingResources}
+	pendingResources = nil
+	if config.AiProvider.Mode == constant.AiProviderModeLocal {
+		return h.StreamChat(ctx, sessionID, msg, opts, emit)
+	}
+	return h.StreamChatOpenAI(ctx, sessionID, msg, opts, emit)
+}
+
+// approve 询问是否执行需要审批的工具调用，输入 y 批准，其余拒绝
+func approve(ctx context.Context, h *host.Host, in *bufio.Scanner, sessionID string, v any) {
+	data, _ := v.(map[string]any)
+	args, _ := json.Marshal(data["args"])
+	fmt.Printf("\n[approve] %v(%s) 需要审批，是否执行？[y/N] ", data["name"], args)
+	approved := in.Scan() && strings.EqualFold(strings.TrimSpace(in.Text()), "y")
+	id, _ := data["id"].(string)
+	if err := h.ResolveApproval(ctx, sessionID, *userFlag, id, approved); err != nil {
+		fmt.Fprintf(os.Stderr, "[error] %v\n", err)
+	}
+}
+
+// render 将 host 推送的事件渲染到终端
+func render(event string, v any) error {
+	data, _ := v.(map[string]any)
+	switch event {
+	case constant.SSEEventDelta:
+		fmt.Print(data["text"])
+	case constant.SSEEventToolCall:
+		args, _ := json.Marshal(data["args"])
+		fmt.Printf("\n[tool] %v(%s)\n", data["name"], args)
+	case constant.SSEEventToolProgress:
+		if total, _ := data["total"].(float64); total > 0 {
+			fmt.Printf("[progress] %v/%v %v\n", data["progress"], total, data["message"])
+		} else {
+			fmt.Printf("[progress] %v %v\n", data["progress"], data["message"])
+		}
+	case constant.SSEEventToolResult:
+		if status := fmt.Sprint(data["status"]); status != constant.ToolOutcomeSuccess {
+			fmt.Printf("[result: %s] %s\n", status, preview(fmt.Sprint(data["error"])))
+		} else if data["cached"] == true {
+			fmt.Printf("[result: cached] %s\n", preview(fmt.Sprint(data["result"])))
+		} else {
+			fmt.Printf("[result] %s\n", preview(fmt.Sprint(data["result"])))
+		}
+		// 终端无法显示图片等二进制内容，只提示类型与大小
+		contents, _ := data["content"].([]mcp_client.Content)
+		for _, c := range contents {
+			if c.Data == "" {
+				continue
+			}
+			label := c.MIMEType
+			if c.URI != "" {
+				label = c.URI + " " + label
+			}
+			fmt.Printf("[%s] %s %d bytes\n", c.Type, label, base64.StdEncoding.DecodedLen(len(c.Data)))
+		}
+	case constant.SSEEventDone:
+		if reason := fmt.Sprint(data["reason"]); reason != "completed" {
+			fmt.Printf("\n[done: %s]", reason)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// command 处理斜杠命令，返回 true 表示退出
+func command(ctx context.Context, h *host.Host, clientSet *base.ClientSet, sessionID, line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "/exit", "/quit":
+		return true
+	case "/help":
+		fmt.Println("/tools           列出可用工具")
+		fmt.Println("/resources       列出 MCP 资源与资源模板")
+		fmt.Println("/attach uri [server]  附带资源，随下一条消息发送")
+		fmt.Println("/history         查看当前会话记录")
+		fmt.Println("/reset           清空当前会话记录")
+		fmt.Println("/model [name]    查看或切换模型")
+		fmt.Println("/exit            退出")
+	case "/tools":
+		for _, t := range clientSet.MCPCli.Tools() {
+			fmt.Printf("- %s: %s\n", t.Name, t.Description)
+		}
+	case "/resources":
+		resources, templates := h.MCPResources(ctx)
+		for _, r := range resources {
+			fmt.Printf("- [%s] %s  %s: %s\n", r.Server, r.URI, r.Name, r.Description)
+		}
+		for _, t := range templates {
+			fmt.Printf("- [%s] %s  %s: %s\n", t.Server, t.URITemplate, t.Name, t.Description)
+		}
+	case "/attach":
+		uri, server, _ := strings.Cut(arg, " ")
+		if uri == "" {
+			fmt.Println("用法：/attach uri [server]")
+			return false
+		}
+		pendingResources = append(pendingResources, host.ResourceRef{Server: strings.TrimSpace(server), URI: uri})
+		fmt.Printf("已附带 %s，将随下一条消息发送\n", uri)
+	case "/history":
+		_, msgs, err := h.GetSession(ctx, sessionID, *userFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[error] %v\n", err)
+			return false
+		}
+		for _, m := range msgs {
+			switch {
+			case m.Role == "tool":
+				fmt.Printf("[tool %s] %s\n", m.ToolName, preview(m.Content))
+			case len(m.ToolCalls) > 0:
+				for _, tc := range m.ToolCalls {
+					fmt.Printf("[%s -> %s] %s\n", m.Role, tc.Function.Name, tc.Function.ArgumentsString())
+				}
+			default:
+				fmt.Printf("[%s] %s\n", m.Role, m.Content)
+			}
+		}
+	case "/reset":
+		if _, err := h.ClearSession(ctx, sessionID, *userFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "[error] %v\n", err)
+			return false
+		}
+		fmt.Println("会话记录已清空")
+	case "/model":
+		if arg != "" {
+			model = arg
+		}
+		fmt.Printf("model: %s\n", cmp.Or(model, config.AiProvider.Model))
+	default:
+		fmt.Printf("未知命令 %s，输入 /help 查看命令\n", name)
+	}
+	return false
+}
+
+func preview(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if r := []rune(s); len(r) > maxResultPreview {
+		return string(r[:maxResultPreview]) + "..."
+	}
+	return s
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/FantasyRL/go-mcp-demo/api/handler/api"
 	"github.com/FantasyRL/go-mcp-demo/api/router"
 	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
 	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
 	"github.com/FantasyRL/go-mcp-demo/pkg/utils"
 	"time"
@@ -59,11 +60,11 @@ func main() {
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"*"},
 		MaxAge:           12 * time.Hour,
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", constant.HeaderSessionID},
 	}))
 
-	// gzip
-	h.Use(gzip.Gzip(gzip.BestSpeed))
+	// gzip（WebSocket 握手不能压缩）
+	h.Use(gzip.Gzip(gzip.BestSpeed, gzip.WithExcludedPaths([]string{"/api/v1/chat/ws"})))
 
 	// Sentinel
 	initSentinel()
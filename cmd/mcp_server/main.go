@@ -21,7 +21,7 @@ func init() {
 	flag.Parse()
 	config.Load(*configPath, serviceName)
 	logger.Init(serviceName, config.GetLoggerLevel())
-	toolSet = tool_set.NewToolSet(tool.WithTimeTool(), tool.WithLongRunningOperationTool(), tool.WithDevRunnerTools())
+	toolSet = tool_set.NewToolSet(tool.WithTimeTool(), tool.WithLongRunningOperationTool(), tool.WithDevRunnerTools(), tool.WithFileResources(config.MCP.Resources.FileDirs), tool.WithLogResources())
 }
 
 func main() {
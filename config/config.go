@@ -9,6 +9,11 @@ import (
 var (
 	AiProvider   *AiProviderConfig
 	CLI          *cliConfig
+	Conversation *conversationConfig
+	SSE          *sseConfig
+	WebSocket    *wsConfig
+	ToolApproval *toolApprovalConfig
+	ToolCache    *toolCacheConfig
 	MCP          *mcpConfig
 	Server       *server
 	Registry     *registryConfig
@@ -32,6 +37,11 @@ func Load(path string, srv string) {
 
 	AiProvider = &cfg.AiProvider
 	CLI = &cfg.CLI
+	Conversation = &cfg.Conversation
+	SSE = &cfg.SSE
+	WebSocket = &cfg.WebSocket
+	ToolApproval = &cfg.ToolApproval
+	ToolCache = &cfg.ToolCache
 	MCP = &cfg.MCP
 	Server = &cfg.Server
 	Registry = &cfg.Registry
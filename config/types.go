@@ -22,11 +22,31 @@ type OllamaOptions struct {
 }
 
 type AiProviderConfig struct {
-	Mode    string                 `mapstructure:"mode"`
-	BaseURL string                 `mapstructure:"base_url"` // e.g. http://127.0.0.1:11434
-	Model   string                 `mapstructure:"model"`    // e.g. qwen3:1.7b
-	Remote  AiProviderRemoteConfig `mapstructure:"remote"`
-	Options OllamaOptions          `mapstructure:"options"`
+	Mode    string `mapstructure:"mode"`
+	BaseURL string `mapstructure:"base_url"` // e.g. http://127.0.0.1:11434
+	Model   string `mapstructure:"model"`    // e.g. qwen3:1.7b
+	// ToolRoundLimit 单次对话最多进行的工具调用轮数，<=0 时使用默认值
+	ToolRoundLimit int `mapstructure:"tool_round_limit"`
+	// ToolParallelism 同一轮中最多同时执行的工具调用数，1 为逐个执行，<=0 时使用默认值
+	ToolParallelism int `mapstructure:"tool_parallelism"`
+	// VisionModels 支持图片输入的模型，其余模型收到的图片以文本占位代替
+	VisionModels []string               `mapstructure:"vision_models"`
+	Remote       AiProviderRemoteConfig `mapstructure:"remote"`
+	Options      OllamaOptions          `mapstructure:"options"`
+	Context      contextConfig          `mapstructure:"context"`
+}
+
+type contextModel struct {
+	Name   string `mapstructure:"name"`   // 模型名，与 ai_provider.model 对应
+	Budget int    `mapstructure:"budget"` // 该模型的上下文预算（token）
+}
+
+// contextConfig 上下文窗口管理，预算<=0 时不做处理
+type contextConfig struct {
+	Budget              int            `mapstructure:"budget"`                 // 默认上下文预算（token）
+	MaxToolOutputTokens int            `mapstructure:"max_tool_output_tokens"` // 超过预算时单条工具输出最多保留的 token 数
+	KeepTurns           int            `mapstructure:"keep_turns"`             // 摘要时原样保留的最近对话轮数
+	Models              []contextModel `mapstructure:"models"`                 // 按模型覆盖预算
 }
 type AiProviderRemoteConfig struct {
 	Provider string `mapstructure:"provider"`
@@ -41,6 +61,43 @@ type cliConfig struct {
 	MaxTurns     int    `mapstructure:"max_turns"`
 }
 
+type conversationFile struct {
+	Dir string `mapstructure:"dir"` // 会话文件目录，如 ./data/conversations
+}
+
+// conversationConfig 对话历史存储
+type conversationConfig struct {
+	Store string           `mapstructure:"store"` // "memory" | "file"
+	File  conversationFile `mapstructure:"file"`
+}
+
+// sseConfig 流式接口断线续传
+type sseConfig struct {
+	BufferTTL        time.Duration `mapstructure:"buffer_ttl"`        // 已结束轮次的事件缓存时长
+	ReconnectTimeout time.Duration `mapstructure:"reconnect_timeout"` // 客户端断开后等待重连的时长，超时取消生成
+}
+
+// wsConfig WebSocket 接口
+type wsConfig struct {
+	// AllowedOrigins 允许跨域连接的来源，如 https://example.com，"*" 允许任意来源；
+	// 与请求同源或不带 Origin（非浏览器客户端）的连接总是允许
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+}
+
+// toolApprovalConfig 工具调用审批策略：auto 直接执行 | require_approval 等待用户批准 | deny 禁止调用
+type toolApprovalConfig struct {
+	Timeout time.Duration     `mapstructure:"timeout"` // 等待审批的时长，超时视为拒绝
+	Default string            `mapstructure:"default"` // 未单独配置的工具使用的策略，为空时为 auto
+	Tools   map[string]string `mapstructure:"tools"`   // 按工具名配置策略
+}
+
+// toolCacheConfig 工具结果缓存：声明了 readOnlyHint 的工具按 TTL 缓存，tools 按工具名覆盖
+type toolCacheConfig struct {
+	Enable bool                     `mapstructure:"enable"` // 是否启用，默认关闭
+	TTL    time.Duration            `mapstructure:"ttl"`    // 默认缓存时长
+	Tools  map[string]time.Duration `mapstructure:"tools"`  // 按工具名指定缓存时长，<=0 表示不缓存
+}
+
 /************ MCP（仅关注自身传输及超时，不再包含 Consul） ************/
 
 type mcpStdio struct {
@@ -52,11 +109,33 @@ type mcpHTTP struct {
 	BaseURL string `mapstructure:"base_url"` // 直连时使用，如 "http://127.0.0.1:8080/mcp"
 }
 
+// MCPServerConfig 单个 MCP Server 的连接配置
+type MCPServerConfig struct {
+	Name      string   `mapstructure:"name"`      // 唯一名称，作为该 server 工具名的前缀
+	Transport string   `mapstructure:"transport"` // "stdio" | "sse" | "http"
+	Stdio     mcpStdio `mapstructure:"stdio"`
+	HTTP      mcpHTTP  `mapstructure:"http"`
+}
+
+// mcpResources MCP Server 提供的资源
+type mcpResources struct {
+	// FileDirs file:// 资源允许访问的目录（相对 mcp_server 的工作目录），为空时不提供；以 . 开头的路径与 config 目录始终不可访问
+	FileDirs []string `mapstructure:"file_dirs"`
+}
+
 type mcpConfig struct {
 	ServerName string   `mapstructure:"server_name"`
 	Transport  string   `mapstructure:"transport"` // "stdio" | "sse" | "http"
 	Stdio      mcpStdio `mapstructure:"stdio"`
 	HTTP       mcpHTTP  `mapstructure:"http"`
+	// Servers 同时连接多个 MCP Server，配置后忽略上面的单 server 配置与 registry
+	Servers []MCPServerConfig `mapstructure:"servers"`
+	// CallTimeout 工具调用的默认超时，<=0 时使用默认值
+	CallTimeout time.Duration `mapstructure:"call_timeout"`
+	// ToolTimeouts 按工具名覆盖超时，多个 server 时使用带前缀的工具名
+	ToolTimeouts map[string]time.Duration `mapstructure:"tool_timeouts"`
+	// Resources mcp_server 提供的资源
+	Resources mcpResources `mapstructure:"resources"`
 }
 
 type consulConfig struct {
@@ -87,9 +166,14 @@ type service struct {
 }
 
 type Config struct {
-	Server     server           `mapstructure:"server"`
-	AiProvider AiProviderConfig `mapstructure:"ai_provider"`
-	CLI        cliConfig        `mapstructure:"cli"`
-	MCP        mcpConfig        `mapstructure:"mcp"`
-	Registry   registryConfig   `mapstructure:"registry"`
+	Server       server             `mapstructure:"server"`
+	AiProvider   AiProviderConfig   `mapstructure:"ai_provider"`
+	CLI          cliConfig          `mapstructure:"cli"`
+	Conversation conversationConfig `mapstructure:"conversation"`
+	SSE          sseConfig          `mapstructure:"sse"`
+	WebSocket    wsConfig           `mapstructure:"websocket"`
+	ToolApproval toolApprovalConfig `mapstructure:"tool_approval"`
+	ToolCache    toolCacheConfig    `mapstructure:"tool_cache"`
+	MCP          mcpConfig          `mapstructure:"mcp"`
+	Registry     registryConfig     `mapstructure:"registry"`
 }
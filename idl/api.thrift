@@ -7,6 +7,16 @@ struct ChatRequest{
         description: "用户发送的消息内容",
         type: "string"
     }')
+    2: optional string session_id(api.body="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID，为空时创建新会话",
+        type: "string"
+    }')
+    3: optional string user_id(api.body="user_id", openapi.property='{
+        title: "用户ID",
+        description: "用户ID，指定后会话只允许该用户访问",
+        type: "string"
+    }')
 }(
     openapi.schema='{
         title: "聊天请求",
@@ -21,11 +31,21 @@ struct ChatResponse{
         description: "AI生成的回复内容",
         type: "string"
     }')
+    2: string session_id(api.body="session_id", openapi.property='{
+        title: "会话ID",
+        description: "本次对话所属的会话ID，后续请求携带以继续对话",
+        type: "string"
+    }')
+    3: string finish_reason(api.body="finish_reason", openapi.property='{
+        title: "结束原因",
+        description: "completed：模型给出了最终回答；tool_round_limit：达到工具调用轮数上限，response 不是最终回答",
+        type: "string"
+    }')
 }(
     openapi.schema='{
         title: "聊天响应",
         description: "包含AI回复的聊天响应",
-        required: ["response"]
+        required: ["response", "session_id", "finish_reason"]
     }'
 )
 
@@ -35,6 +55,21 @@ struct ChatSSEHandlerRequest{
         description: "用户发送的消息内容",
         type: "string"
     }')
+    2: optional string session_id(api.query="session_id",openapi.property='{
+        title: "会话ID",
+        description: "会话ID，为空时创建新会话，实际使用的会话ID通过响应头 X-Session-Id 返回",
+        type: "string"
+    }')
+    3: optional string user_id(api.query="user_id",openapi.property='{
+        title: "用户ID",
+        description: "用户ID，指定后会话只允许该用户访问",
+        type: "string"
+    }')
+    4: optional string last_event_id(api.header="Last-Event-ID",openapi.property='{
+        title: "最后事件ID",
+        description: "断线重连时携带已收到的最后一个事件 id（需同时携带 session_id），服务端从其后继续推送，此时忽略 message",
+        type: "string"
+    }')
 }(
      openapi.schema='{
          title: "流式聊天请求",
@@ -43,17 +78,739 @@ struct ChatSSEHandlerRequest{
      }'
 )
 
+struct ChatStreamRequest{
+    1: string message(api.body="message", openapi.property='{
+        title: "用户消息",
+        description: "用户发送的消息内容",
+        type: "string"
+    }')
+    2: optional string session_id(api.body="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID，为空时创建新会话，实际使用的会话ID通过响应头 X-Session-Id 返回",
+        type: "string"
+    }')
+    3: optional string user_id(api.body="user_id", openapi.property='{
+        title: "用户ID",
+        description: "用户ID，指定后会话只允许该用户访问",
+        type: "string"
+    }')
+    4: optional string model(api.body="model", openapi.property='{
+        title: "模型",
+        description: "本次对话使用的模型，为空时使用配置中的模型",
+        type: "string"
+    }')
+    5: optional double temperature(api.body="temperature", openapi.property='{
+        title: "温度",
+        description: "本次对话的采样温度，为空时使用配置",
+        type: "number"
+    }')
+    6: optional list<string> tools(api.body="tools", openapi.property='{
+        title: "工具白名单",
+        description: "本次对话允许调用的工具名，为空时可使用全部工具",
+        type: "array"
+    }')
+    7: optional list<model.Attachment> attachments(api.body="attachments", openapi.property='{
+        title: "附件",
+        description: "随用户消息发送的附件",
+        type: "array"
+    }')
+    8: optional string last_event_id(api.header="Last-Event-ID",openapi.property='{
+        title: "最后事件ID",
+        description: "断线重连时携带已收到的最后一个事件 id（需同时携带 session_id），服务端从其后继续推送，此时忽略其余参数",
+        type: "string"
+    }')
+    9: optional list<model.ResourceRef> resources(api.body="resources", openapi.property='{
+        title: "资源",
+        description: "随用户消息附带的 MCP 资源，见 /api/v1/mcp/resources",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "流式聊天请求",
+        description: "以 JSON 请求体发起的流式聊天，响应与 /api/v1/chat/sse 相同",
+        required: ["message"]
+    }'
+)
+
+struct ChatWSRequest{
+    1: optional string session_id(api.query="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID，为空时创建新会话，实际使用的会话ID通过连接后的首个 session 消息返回",
+        type: "string"
+    }')
+    2: optional string user_id(api.query="user_id", openapi.property='{
+        title: "用户ID",
+        description: "用户ID，指定后会话只允许该用户访问",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "WebSocket 对话请求",
+        description: "升级为 WebSocket 连接，服务端推送与 /api/v1/chat/sse 相同的事件，客户端可随时发送控制消息"
+    }'
+)
+
+struct WSClientFrame{
+    1: string type(api.body="type", openapi.property='{
+        title: "消息类型",
+        description: "user_message 发送消息（正在生成时排队到本轮结束后）| cancel 取消正在生成的回复 | approve_tool 批准工具调用 | deny_tool 拒绝工具调用",
+        type: "string"
+    }')
+    2: optional string message(api.body="message", openapi.property='{
+        title: "用户消息",
+        description: "user_message 的消息内容",
+        type: "string"
+    }')
+    3: optional string model(api.body="model", openapi.property='{
+        title: "模型",
+        description: "user_message 本轮使用的模型，为空时使用配置中的模型",
+        type: "string"
+    }')
+    4: optional double temperature(api.body="temperature", openapi.property='{
+        title: "温度",
+        description: "user_message 本轮的采样温度，为空时使用配置",
+        type: "number"
+    }')
+    5: optional list<string> tools(api.body="tools", openapi.property='{
+        title: "工具白名单",
+        description: "user_message 本轮允许调用的工具名，为空时可使用全部工具",
+        type: "array"
+    }')
+    6: optional list<model.Attachment> attachments(api.body="attachments", openapi.property='{
+        title: "附件",
+        description: "user_message 随消息发送的附件",
+        type: "array"
+    }')
+    7: optional bool require_approval(api.body="require_approval", openapi.property='{
+        title: "需要审批",
+        description: "user_message 本轮的每次工具调用都需等待 approve_tool / deny_tool",
+        type: "boolean"
+    }')
+    8: optional string tool_call_id(api.body="tool_call_id", openapi.property='{
+        title: "工具调用ID",
+        description: "approve_tool / deny_tool 针对的工具调用 id，对应 tool_call 事件中的 id",
+        type: "string"
+    }')
+    9: optional list<model.ResourceRef> resources(api.body="resources", openapi.property='{
+        title: "资源",
+        description: "user_message 随消息附带的 MCP 资源，见 /api/v1/mcp/resources",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "WebSocket 客户端消息",
+        description: "客户端通过 WebSocket 发送的 JSON 文本消息",
+        required: ["type"]
+    }'
+)
+
+struct SSEDeltaEvent{
+    1: string text(api.body="text", openapi.property='{
+        title: "增量文本",
+        description: "模型新生成的文本片段",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "delta 事件",
+        description: "event: delta，模型内容增量",
+        required: ["text"]
+    }'
+)
+
+struct SSEStartToolCallEvent{
+    1: i64 round(api.body="round", openapi.property='{
+        title: "轮次",
+        description: "工具调用轮次，从 1 开始",
+        type: "integer"
+    }')
+    2: list<model.ToolCall> tool_calls(api.body="tool_calls", openapi.property='{
+        title: "工具调用",
+        description: "本轮模型请求的全部工具调用",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "start_tool_call 事件",
+        description: "event: start_tool_call，模型请求调用工具，随后逐个执行",
+        required: ["round", "tool_calls"]
+    }'
+)
+
+struct SSEToolCallEvent{
+    1: i64 round(api.body="round", openapi.property='{
+        title: "轮次",
+        description: "工具调用轮次，从 1 开始",
+        type: "integer"
+    }')
+    2: string id(api.body="id", openapi.property='{
+        title: "工具调用ID",
+        description: "工具调用ID，与 tool_result 事件的 id 对应",
+        type: "string"
+    }')
+    3: string name(api.body="name", openapi.property='{
+        title: "工具名",
+        description: "调用的工具名",
+        type: "string"
+    }')
+    4: string args(api.body="args", openapi.property='{
+        title: "调用参数",
+        description: "解析后的调用参数（JSON 对象）",
+        type: "object"
+    }')
+}(
+    openapi.schema='{
+        title: "tool_call 事件",
+        description: "event: tool_call，开始执行某个工具",
+        required: ["round", "id", "name", "args"]
+    }'
+)
+
+struct SSEToolResultEvent{
+    1: i64 round(api.body="round", openapi.property='{
+        title: "轮次",
+        description: "工具调用轮次，从 1 开始",
+        type: "integer"
+    }')
+    2: string id(api.body="id", openapi.property='{
+        title: "工具调用ID",
+        description: "对应 tool_call 事件的 id",
+        type: "string"
+    }')
+    3: string name(api.body="name", openapi.property='{
+        title: "工具名",
+        description: "调用的工具名",
+        type: "string"
+    }')
+    4: string result(api.body="result", openapi.property='{
+        title: "工具结果",
+        description: "交给模型的工具结果文本，图片等非文本内容以占位说明代替",
+        type: "string"
+    }')
+    5: list<model.ToolResultContent> content(api.body="content", openapi.property='{
+        title: "结果内容",
+        description: "按类型区分的全部内容，图片等以 base64 给出",
+        type: "array"
+    }')
+    6: string status(api.body="status", openapi.property='{
+        title: "结果分类",
+        description: "success | tool_error | transport_error | timeout | denied | cancelled",
+        type: "string"
+    }')
+    7: optional string error(api.body="error", openapi.property='{
+        title: "失败原因",
+        description: "调用失败时的原因，成功时不返回",
+        type: "string"
+    }')
+    8: optional bool cached(api.body="cached", openapi.property='{
+        title: "来自缓存",
+        description: "为 true 时结果来自工具结果缓存，未调用 MCP Server",
+        type: "boolean"
+    }')
+}(
+    openapi.schema='{
+        title: "tool_result 事件",
+        description: "event: tool_result，工具执行完成",
+        required: ["round", "id", "name", "result", "content", "status"]
+    }'
+)
+
+struct SSEToolProgressEvent{
+    1: i64 round(api.body="round", openapi.property='{
+        title: "轮次",
+        description: "工具调用轮次，从 1 开始",
+        type: "integer"
+    }')
+    2: string id(api.body="id", openapi.property='{
+        title: "工具调用ID",
+        description: "对应 tool_call 事件的 id",
+        type: "string"
+    }')
+    3: string name(api.body="name", openapi.property='{
+        title: "工具名",
+        description: "调用的工具名",
+        type: "string"
+    }')
+    4: double progress(api.body="progress", openapi.property='{
+        title: "当前进度",
+        description: "工具上报的进度值",
+        type: "number"
+    }')
+    5: double total(api.body="total", openapi.property='{
+        title: "总量",
+        description: "进度的总量，0 表示未知",
+        type: "number"
+    }')
+    6: string message(api.body="message", openapi.property='{
+        title: "进度消息",
+        description: "工具上报的进度说明，可能为空",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "tool_progress 事件",
+        description: "event: tool_progress，工具执行中上报的进度（MCP notifications/progress），位于对应的 tool_call 与 tool_result 之间",
+        required: ["round", "id", "name", "progress", "total", "message"]
+    }'
+)
+
+struct SSEApprovalRequiredEvent{
+    1: i64 round(api.body="round", openapi.property='{
+        title: "轮次",
+        description: "工具调用轮次，从 1 开始",
+        type: "integer"
+    }')
+    2: string id(api.body="id", openapi.property='{
+        title: "工具调用ID",
+        description: "等待审批的工具调用ID，审批时作为 tool_call_id",
+        type: "string"
+    }')
+    3: string name(api.body="name", openapi.property='{
+        title: "工具名",
+        description: "调用的工具名",
+        type: "string"
+    }')
+    4: string args(api.body="args", openapi.property='{
+        title: "调用参数",
+        description: "解析后的调用参数",
+        type: "object"
+    }')
+    5: i64 expires_at(api.body="expires_at", openapi.property='{
+        title: "超时时间",
+        description: "unix 毫秒时间戳，届时仍未审批视为拒绝",
+        type: "integer"
+    }')
+}(
+    openapi.schema='{
+        title: "approval_required 事件",
+        description: "event: approval_required，工具调用按策略需要审批，本轮暂停直到通过 /api/v1/sessions/{session_id}/approvals（或 WebSocket 控制消息）批准或拒绝",
+        required: ["round", "id", "name", "args", "expires_at"]
+    }'
+)
+
+struct SSEDoneEvent{
+    1: string reason(api.body="reason", openapi.property='{
+        title: "结束原因",
+        description: "completed | tool_round_limit | no_tool_details | cancelled（被取消或无人重连）",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "done 事件",
+        description: "event: done，本轮对话结束，之后不再有事件",
+        required: ["reason"]
+    }'
+)
+
+struct SSEErrorEvent{
+    1: string error(api.body="error", openapi.property='{
+        title: "错误信息",
+        description: "错误信息",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "error 事件",
+        description: "event: error，对话出错，之后不再有事件",
+        required: ["error"]
+    }'
+)
+
 struct ChatSSEHandlerResponse{
-    1: string response(api.body="response", openapi.property='{
-        title: "AI回复片段",
-        description: "AI生成的回复片段",
+    1: optional SSEDeltaEvent delta(api.body="delta", openapi.property='{
+        title: "delta",
+        description: "event: delta 的 data"
+    }')
+    2: optional SSEStartToolCallEvent start_tool_call(api.body="start_tool_call", openapi.property='{
+        title: "start_tool_call",
+        description: "event: start_tool_call 的 data"
+    }')
+    3: optional SSEToolCallEvent tool_call(api.body="tool_call", openapi.property='{
+        title: "tool_call",
+        description: "event: tool_call 的 data"
+    }')
+    4: optional SSEToolResultEvent tool_result(api.body="tool_result", openapi.property='{
+        title: "tool_result",
+        description: "event: tool_result 的 data"
+    }')
+    5: optional SSEDoneEvent done(api.body="done", openapi.property='{
+        title: "done",
+        description: "event: done 的 data"
+    }')
+    6: optional SSEErrorEvent error(api.body="error", openapi.property='{
+        title: "error",
+        description: "event: error 的 data"
+    }')
+    7: optional SSEApprovalRequiredEvent approval_required(api.body="approval_required", openapi.property='{
+        title: "approval_required",
+        description: "event: approval_required 的 data"
+    }')
+    8: optional SSEToolProgressEvent tool_progress(api.body="tool_progress", openapi.property='{
+        title: "tool_progress",
+        description: "event: tool_progress 的 data"
+    }')
+}(
+    openapi.schema='{
+        title: "流式聊天事件",
+        description: "text/event-stream：每个事件包含 event（事件名）、id（单调递增）与 data（JSON），本结构按事件名列出各事件 data 的结构，并非实际响应体"
+    }'
+)
+
+struct CreateSessionRequest{
+    1: optional string user_id(api.body="user_id", openapi.property='{
+        title: "用户ID",
+        description: "用户ID，指定后会话只允许该用户访问",
+        type: "string"
+    }')
+    2: optional string title(api.body="title", openapi.property='{
+        title: "标题",
+        description: "会话标题",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "创建会话请求",
+        description: "创建一个新的空会话"
+    }'
+)
+
+struct CreateSessionResponse{
+    1: model.Session session(api.body="session", openapi.property='{
+        title: "会话",
+        description: "新创建的会话"
+    }')
+}(
+    openapi.schema='{
+        title: "创建会话响应",
+        description: "新创建的会话信息",
+        required: ["session"]
+    }'
+)
+
+struct ListSessionsRequest{
+    1: optional string user_id(api.query="user_id", openapi.property='{
+        title: "用户ID",
+        description: "只列出该用户的会话，为空时列出未绑定用户的会话",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "会话列表请求",
+        description: "按用户列出会话"
+    }'
+)
+
+struct ListSessionsResponse{
+    1: list<model.Session> sessions(api.body="sessions", openapi.property='{
+        title: "会话列表",
+        description: "按最近更新时间倒序排列的会话",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "会话列表响应",
+        description: "会话列表",
+        required: ["sessions"]
+    }'
+)
+
+struct GetSessionRequest{
+    1: string session_id(api.path="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID",
+        type: "string"
+    }')
+    2: optional string user_id(api.query="user_id", openapi.property='{
+        title: "用户ID",
+        description: "请求方用户ID",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "获取会话请求",
+        description: "获取会话的完整记录",
+        required: ["session_id"]
+    }'
+)
+
+struct GetSessionResponse{
+    1: model.Session session(api.body="session", openapi.property='{
+        title: "会话",
+        description: "会话信息"
+    }')
+    2: list<model.SessionMessage> messages(api.body="messages", openapi.property='{
+        title: "消息记录",
+        description: "会话的完整消息记录，包含工具调用与工具结果",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "获取会话响应",
+        description: "会话信息及完整消息记录",
+        required: ["session", "messages"]
+    }'
+)
+
+struct RenameSessionRequest{
+    1: string session_id(api.path="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID",
+        type: "string"
+    }')
+    2: string title(api.body="title", openapi.property='{
+        title: "标题",
+        description: "新的会话标题",
+        type: "string"
+    }')
+    3: optional string user_id(api.body="user_id", openapi.property='{
+        title: "用户ID",
+        description: "请求方用户ID",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "重命名会话请求",
+        description: "修改会话标题",
+        required: ["session_id", "title"]
+    }'
+)
+
+struct RenameSessionResponse{
+    1: model.Session session(api.body="session", openapi.property='{
+        title: "会话",
+        description: "修改后的会话信息"
+    }')
+}(
+    openapi.schema='{
+        title: "重命名会话响应",
+        description: "修改后的会话信息",
+        required: ["session"]
+    }'
+)
+
+struct DeleteSessionRequest{
+    1: string session_id(api.path="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID",
+        type: "string"
+    }')
+    2: optional string user_id(api.query="user_id", openapi.property='{
+        title: "用户ID",
+        description: "请求方用户ID",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "删除会话请求",
+        description: "删除会话及其全部记录",
+        required: ["session_id"]
+    }'
+)
+
+struct DeleteSessionResponse{
+    1: string session_id(api.body="session_id", openapi.property='{
+        title: "会话ID",
+        description: "已删除的会话ID",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "删除会话响应",
+        description: "已删除的会话ID",
+        required: ["session_id"]
+    }'
+)
+
+struct ClearSessionRequest{
+    1: string session_id(api.path="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID",
+        type: "string"
+    }')
+    2: optional string user_id(api.body="user_id", openapi.property='{
+        title: "用户ID",
+        description: "请求方用户ID",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "清空会话请求",
+        description: "清空会话消息记录，保留会话本身",
+        required: ["session_id"]
+    }'
+)
+
+struct ClearSessionResponse{
+    1: model.Session session(api.body="session", openapi.property='{
+        title: "会话",
+        description: "清空后的会话信息"
+    }')
+}(
+    openapi.schema='{
+        title: "清空会话响应",
+        description: "清空后的会话信息",
+        required: ["session"]
+    }'
+)
+
+struct ListApprovalsRequest{
+    1: string session_id(api.path="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID",
+        type: "string"
+    }')
+    2: optional string user_id(api.query="user_id", openapi.property='{
+        title: "用户ID",
+        description: "请求方用户ID",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "待审批列表请求",
+        description: "列出会话中等待审批的工具调用",
+        required: ["session_id"]
+    }'
+)
+
+struct ListApprovalsResponse{
+    1: list<model.ToolApproval> approvals(api.body="approvals", openapi.property='{
+        title: "待审批的工具调用",
+        description: "按开始等待的时间排列",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "待审批列表响应",
+        description: "会话中等待审批的工具调用",
+        required: ["approvals"]
+    }'
+)
+
+struct ResolveApprovalRequest{
+    1: string session_id(api.path="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID",
+        type: "string"
+    }')
+    2: string tool_call_id(api.body="tool_call_id", openapi.property='{
+        title: "工具调用ID",
+        description: "approval_required 事件中的 id",
+        type: "string"
+    }')
+    3: bool approved(api.body="approved", openapi.property='{
+        title: "是否批准",
+        description: "true 批准执行，false 拒绝",
+        type: "boolean"
+    }')
+    4: optional string user_id(api.body="user_id", openapi.property='{
+        title: "用户ID",
+        description: "请求方用户ID",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "审批请求",
+        description: "批准或拒绝一个等待中的工具调用",
+        required: ["session_id", "tool_call_id", "approved"]
+    }'
+)
+
+struct ResolveApprovalResponse{
+    1: string tool_call_id(api.body="tool_call_id", openapi.property='{
+        title: "工具调用ID",
+        description: "已处理的工具调用ID",
+        type: "string"
+    }')
+    2: bool approved(api.body="approved", openapi.property='{
+        title: "是否批准",
+        description: "做出的决定",
+        type: "boolean"
+    }')
+}(
+    openapi.schema='{
+        title: "审批响应",
+        description: "已做出的审批决定",
+        required: ["tool_call_id", "approved"]
+    }'
+)
+
+struct ListMCPServersRequest{
+}(
+    openapi.schema='{
+        title: "MCP Server 列表请求",
+        description: "查看 host 连接的 MCP Server 的健康状态"
+    }'
+)
+
+struct ListMCPServersResponse{
+    1: list<model.MCPServerStatus> servers(api.body="servers", openapi.property='{
+        title: "MCP Server",
+        description: "按配置顺序排列",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "MCP Server 列表响应",
+        description: "各 MCP Server 的健康状态",
+        required: ["servers"]
+    }'
+)
+
+struct ListMCPResourcesRequest{
+}(
+    openapi.schema='{
+        title: "MCP 资源列表请求",
+        description: "查看各 MCP Server 提供的资源与资源模板"
+    }'
+)
+struct ListMCPResourcesResponse{
+    1: list<model.MCPResource> resources(api.body="resources", openapi.property='{
+        title: "资源",
+        description: "按 server 的配置顺序排列",
+        type: "array"
+    }')
+    2: list<model.MCPResourceTemplate> templates(api.body="templates", openapi.property='{
+        title: "资源模板",
+        description: "按 server 的配置顺序排列",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "MCP 资源列表响应",
+        description: "未连接或不支持资源的 server 不在列表中",
+        required: ["resources", "templates"]
+    }'
+)
+
+struct ReadMCPResourceRequest{
+    1: optional string server(api.query="server", openapi.property='{
+        title: "服务名",
+        description: "资源所属的 MCP Server 名称，只配置了一个 server 时可为空",
+        type: "string"
+    }')
+    2: string uri(api.query="uri", openapi.property='{
+        title: "URI",
+        description: "资源 URI，可以是资源列表中的 uri，也可以是按资源模板展开的 URI",
         type: "string"
     }')
 }(
     openapi.schema='{
-        title: "流式聊天响应",
-        description: "包含AI回复片段的流式聊天响应",
-        required: ["response"]
+        title: "读取 MCP 资源请求",
+        description: "读取 MCP Server 提供的资源",
+        required: ["uri"]
+    }'
+)
+struct ReadMCPResourceResponse{
+    1: list<model.ToolResultContent> contents(api.body="contents", openapi.property='{
+        title: "内容",
+        description: "资源内容，type 均为 resource，文本资源为 text，二进制资源为 base64 的 data",
+        type: "array"
+    }')
+}(
+    openapi.schema='{
+        title: "读取 MCP 资源响应",
+        description: "MCP 资源的内容",
+        required: ["contents"]
     }'
 )
 
@@ -62,4 +819,32 @@ service ApiService {
     ChatResponse Chat(1: ChatRequest req)(api.post="/api/v1/chat")
     // 流式对话
     ChatSSEHandlerResponse ChatSSE(1: ChatSSEHandlerRequest req)(api.get="/api/v1/chat/sse")
+    // 流式对话（JSON 请求体）
+    ChatSSEHandlerResponse ChatStream(1: ChatStreamRequest req)(api.post="/api/v1/chat/stream")
+    // WebSocket 对话
+    ChatSSEHandlerResponse ChatWS(1: ChatWSRequest req)(api.get="/api/v1/chat/ws")
+
+    // 创建会话
+    CreateSessionResponse CreateSession(1: CreateSessionRequest req)(api.post="/api/v1/sessions")
+    // 会话列表
+    ListSessionsResponse ListSessions(1: ListSessionsRequest req)(api.get="/api/v1/sessions")
+    // 获取会话记录
+    GetSessionResponse GetSession(1: GetSessionRequest req)(api.get="/api/v1/sessions/:session_id")
+    // 重命名会话
+    RenameSessionResponse RenameSession(1: RenameSessionRequest req)(api.put="/api/v1/sessions/:session_id")
+    // 删除会话
+    DeleteSessionResponse DeleteSession(1: DeleteSessionRequest req)(api.delete="/api/v1/sessions/:session_id")
+    // 清空会话记录
+    ClearSessionResponse ClearSession(1: ClearSessionRequest req)(api.post="/api/v1/sessions/:session_id/clear")
+    // 待审批的工具调用
+    ListApprovalsResponse ListApprovals(1: ListApprovalsRequest req)(api.get="/api/v1/sessions/:session_id/approvals")
+    // 审批工具调用
+    ResolveApprovalResponse ResolveApproval(1: ResolveApprovalRequest req)(api.post="/api/v1/sessions/:session_id/approvals")
+
+    // MCP Server 健康状态
+    ListMCPServersResponse ListMCPServers(1: ListMCPServersRequest req)(api.get="/api/v1/admin/mcp/servers")
+    // MCP 资源与资源模板
+    ListMCPResourcesResponse ListMCPResources(1: ListMCPResourcesRequest req)(api.get="/api/v1/mcp/resources")
+    // 读取 MCP 资源
+    ReadMCPResourceResponse ReadMCPResource(1: ReadMCPResourceRequest req)(api.get="/api/v1/mcp/resources/read")
 }
\ No newline at end of file
@@ -17,4 +17,328 @@ struct BaseResp {
         description: "所有响应的基础结构",
         required: ["code", "msg"]
     }'
-)
\ No newline at end of file
+)
+struct Session {
+    1: string session_id (api.body="session_id", openapi.property='{
+        title: "会话ID",
+        description: "会话ID",
+        type: "string"
+    }')
+    2: string title (api.body="title", openapi.property='{
+        title: "标题",
+        description: "会话标题",
+        type: "string"
+    }')
+    3: string user_id (api.body="user_id", openapi.property='{
+        title: "用户ID",
+        description: "会话所属用户，为空表示不限定用户",
+        type: "string"
+    }')
+    4: i64 message_count (api.body="message_count", openapi.property='{
+        title: "消息数",
+        description: "会话中的消息条数（含工具调用与工具结果）",
+        type: "integer"
+    }')
+    5: i64 created_at (api.body="created_at", openapi.property='{
+        title: "创建时间",
+        description: "unix 毫秒时间戳",
+        type: "integer"
+    }')
+    6: i64 updated_at (api.body="updated_at", openapi.property='{
+        title: "更新时间",
+        description: "unix 毫秒时间戳",
+        type: "integer"
+    }')
+}(
+    openapi.schema='{
+        title: "会话",
+        description: "会话概要信息",
+        required: ["session_id", "title", "user_id", "message_count", "created_at", "updated_at"]
+    }'
+)
+
+struct ToolCall {
+    1: string id (api.body="id", openapi.property='{
+        title: "工具调用ID",
+        description: "工具调用ID，与 tool 消息的 tool_call_id 对应",
+        type: "string"
+    }')
+    2: string name (api.body="name", openapi.property='{
+        title: "工具名",
+        description: "调用的工具名",
+        type: "string"
+    }')
+    3: string arguments (api.body="arguments", openapi.property='{
+        title: "调用参数",
+        description: "JSON 格式的调用参数",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "工具调用",
+        description: "模型发起的一次工具调用",
+        required: ["id", "name", "arguments"]
+    }'
+)
+
+struct ToolResultContent {
+    1: string type (api.body="type", openapi.property='{
+        title: "类型",
+        description: "text | image | audio | resource",
+        type: "string"
+    }')
+    2: optional string text (api.body="text", openapi.property='{
+        title: "文本",
+        description: "text 的文本，文本类 resource 的内容",
+        type: "string"
+    }')
+    3: optional string mime_type (api.body="mime_type", openapi.property='{
+        title: "MIME 类型",
+        description: "image、audio、resource 的 MIME 类型",
+        type: "string"
+    }')
+    4: optional string data (api.body="data", openapi.property='{
+        title: "数据",
+        description: "image、audio 与二进制 resource 的 base64 数据",
+        type: "string"
+    }')
+    5: optional string uri (api.body="uri", openapi.property='{
+        title: "URI",
+        description: "resource 的 URI",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "工具结果内容",
+        description: "工具结果中的一项内容",
+        required: ["type"]
+    }'
+)
+
+struct SessionMessage {
+    1: string role (api.body="role", openapi.property='{
+        title: "角色",
+        description: "system | user | assistant | tool",
+        type: "string"
+    }')
+    2: string content (api.body="content", openapi.property='{
+        title: "内容",
+        description: "消息文本；tool 消息为工具执行结果",
+        type: "string"
+    }')
+    3: optional list<ToolCall> tool_calls (api.body="tool_calls", openapi.property='{
+        title: "工具调用",
+        description: "assistant 消息发起的工具调用",
+        type: "array"
+    }')
+    4: optional string tool_call_id (api.body="tool_call_id", openapi.property='{
+        title: "工具调用ID",
+        description: "tool 消息对应的工具调用ID",
+        type: "string"
+    }')
+    5: optional string tool_name (api.body="tool_name", openapi.property='{
+        title: "工具名",
+        description: "tool 消息对应的工具名",
+        type: "string"
+    }')
+    6: optional string status (api.body="status", openapi.property='{
+        title: "状态",
+        description: "非空表示该轮对话未正常结束，cancelled 表示客户端断开导致取消",
+        type: "string"
+    }')
+    7: optional string tool_status (api.body="tool_status", openapi.property='{
+        title: "工具调用结果",
+        description: "tool 消息对应调用的结果分类：success | tool_error | transport_error | timeout | denied | cancelled",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "会话消息",
+        description: "会话记录中的一条消息",
+        required: ["role", "content"]
+    }'
+)
+
+struct Attachment {
+    1: string name (api.body="name", openapi.property='{
+        title: "文件名",
+        description: "附件文件名",
+        type: "string"
+    }')
+    2: string content_type (api.body="content_type", openapi.property='{
+        title: "类型",
+        description: "MIME 类型，支持 text/*、application/json 等文本类型以及 image/*",
+        type: "string"
+    }')
+    3: string content (api.body="content", openapi.property='{
+        title: "内容",
+        description: "文本类型为原文，图片为 base64 编码",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "附件",
+        description: "随用户消息发送的附件",
+        required: ["name", "content_type", "content"]
+    }'
+)
+
+struct ToolApproval {
+    1: string tool_call_id (api.body="tool_call_id", openapi.property='{
+        title: "工具调用ID",
+        description: "等待审批的工具调用ID，对应 approval_required 事件中的 id",
+        type: "string"
+    }')
+    2: string name (api.body="name", openapi.property='{
+        title: "工具名",
+        description: "调用的工具名",
+        type: "string"
+    }')
+    3: string arguments (api.body="arguments", openapi.property='{
+        title: "调用参数",
+        description: "JSON 格式的调用参数",
+        type: "string"
+    }')
+    4: i64 created_at (api.body="created_at", openapi.property='{
+        title: "开始等待时间",
+        description: "unix 毫秒时间戳",
+        type: "integer"
+    }')
+    5: i64 expires_at (api.body="expires_at", openapi.property='{
+        title: "超时时间",
+        description: "unix 毫秒时间戳，届时仍未审批视为拒绝",
+        type: "integer"
+    }')
+}(
+    openapi.schema='{
+        title: "待审批的工具调用",
+        description: "按策略需要用户批准后才会执行的工具调用",
+        required: ["tool_call_id", "name", "arguments", "created_at", "expires_at"]
+    }'
+)
+
+struct MCPServerStatus {
+    1: string name (api.body="name", openapi.property='{
+        title: "服务名",
+        description: "配置中的 MCP Server 名称，多个 server 时作为工具名前缀",
+        type: "string"
+    }')
+    2: string transport (api.body="transport", openapi.property='{
+        title: "传输方式",
+        description: "stdio | sse | http",
+        type: "string"
+    }')
+    3: bool healthy (api.body="healthy", openapi.property='{
+        title: "是否可用",
+        description: "连接成功且 ping 正常",
+        type: "boolean"
+    }')
+    4: list<string> tools (api.body="tools", openapi.property='{
+        title: "工具",
+        description: "该 server 提供的工具名（对话中使用的名称）",
+        type: "array"
+    }')
+    5: optional string error (api.body="error", openapi.property='{
+        title: "错误",
+        description: "不可用的原因",
+        type: "string"
+    }')
+    6: i64 checked_at (api.body="checked_at", openapi.property='{
+        title: "检查时间",
+        description: "unix 毫秒时间戳",
+        type: "integer"
+    }')
+}(
+    openapi.schema='{
+        title: "MCP Server 状态",
+        description: "host 连接的 MCP Server 的健康状态",
+        required: ["name", "transport", "healthy", "tools", "checked_at"]
+    }'
+)
+
+struct ResourceRef {
+    1: optional string server (api.body="server", openapi.property='{
+        title: "服务名",
+        description: "资源所属的 MCP Server 名称，只配置了一个 server 时可为空",
+        type: "string"
+    }')
+    2: string uri (api.body="uri", openapi.property='{
+        title: "URI",
+        description: "资源 URI，可以是资源列表中的 uri，也可以是按资源模板展开的 URI",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "资源引用",
+        description: "随用户消息附带的 MCP 资源，读取后文本内联到消息中，图片作为图片发给模型",
+        required: ["uri"]
+    }'
+)
+
+struct MCPResource {
+    1: string server (api.body="server", openapi.property='{
+        title: "服务名",
+        description: "资源所属的 MCP Server 名称",
+        type: "string"
+    }')
+    2: string uri (api.body="uri", openapi.property='{
+        title: "URI",
+        description: "资源 URI",
+        type: "string"
+    }')
+    3: string name (api.body="name", openapi.property='{
+        title: "名称",
+        description: "资源名称",
+        type: "string"
+    }')
+    4: optional string description (api.body="description", openapi.property='{
+        title: "描述",
+        description: "资源描述",
+        type: "string"
+    }')
+    5: optional string mime_type (api.body="mime_type", openapi.property='{
+        title: "MIME 类型",
+        description: "资源的 MIME 类型",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "MCP 资源",
+        description: "MCP Server 提供的资源",
+        required: ["server", "uri", "name"]
+    }'
+)
+
+struct MCPResourceTemplate {
+    1: string server (api.body="server", openapi.property='{
+        title: "服务名",
+        description: "资源模板所属的 MCP Server 名称",
+        type: "string"
+    }')
+    2: string uri_template (api.body="uri_template", openapi.property='{
+        title: "URI 模板",
+        description: "RFC 6570 URI 模板，展开后即为可读取的资源 URI",
+        type: "string"
+    }')
+    3: string name (api.body="name", openapi.property='{
+        title: "名称",
+        description: "资源模板名称",
+        type: "string"
+    }')
+    4: optional string description (api.body="description", openapi.property='{
+        title: "描述",
+        description: "资源模板描述",
+        type: "string"
+    }')
+    5: optional string mime_type (api.body="mime_type", openapi.property='{
+        title: "MIME 类型",
+        description: "匹配该模板的资源的 MIME 类型",
+        type: "string"
+    }')
+}(
+    openapi.schema='{
+        title: "MCP 资源模板",
+        description: "MCP Server 提供的资源模板",
+        required: ["server", "uri_template", "name"]
+    }'
+)
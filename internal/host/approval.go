@@ -0,0 +1,29 @@
+package host
+
+import (
+	"context"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_approval"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+)
+
+// ListApprovals 列出会话中等待审批的工具调用
+func (h *Host) ListApprovals(ctx context.Context, sessionID, userID string) ([]tool_approval.Request, error) {
+	if _, err := h.session(ctx, sessionID, userID); err != nil {
+		return nil, err
+	}
+	if h.approvals == nil {
+		return nil, nil
+	}
+	return h.approvals.Pending(sessionID), nil
+}
+
+// ResolveApproval 批准或拒绝会话中等待审批的工具调用
+func (h *Host) ResolveApproval(ctx context.Context, sessionID, userID, toolCallID string, approved bool) error {
+	if _, err := h.session(ctx, sessionID, userID); err != nil {
+		return err
+	}
+	if h.approvals == nil {
+		return errno.ApprovalNotExist
+	}
+	return h.approvals.Resolve(sessionID, toolCallID, approved)
+}
@@ -7,14 +7,19 @@ import (
 	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
 	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
 	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/google/uuid"
 )
 
-func (h *Host) Chat(id int64, msg string) (string, error) {
-	// 获取当前用户的对话历史（如果没有则初始化为空切片）
-	userHistory := history[id]
-	if userHistory == nil {
-		userHistory = []ai_provider.Message{}
+// Chat 非流式对话，返回最后一次生成的回复与结束原因：completed | tool_round_limit，
+// 达到工具调用轮数上限时回复为模型最后一次请求工具时附带的内容，不是最终回答
+func (h *Host) Chat(sessionID string, msg string) (string, string, error) {
+	// 获取当前用户的对话历史
+	userHistory, err := h.loadHistory(h.ctx, sessionID)
+	if err != nil {
+		return "", "", err
 	}
+	// 本轮新增的消息，结束时统一追加到存储
+	turnStart := len(userHistory)
 
 	// 将当前用户消息加入历史
 	userHistory = append(userHistory, ai_provider.Message{Role: "user", Content: msg})
@@ -23,180 +28,200 @@ func (h *Host) Chat(id int64, msg string) (string, error) {
 	ollamaTools := h.mcpCli.ConvertToolsToOllama()
 	ollamaOptions := ai_provider.BuildOptions()
 
-	// 第一次调用模型（带历史）
-	resp, err := h.aiProviderCli.Chat(h.ctx, ai_provider.ChatRequest{
-		Model:     config.AiProvider.Model,
-		Messages:  userHistory, // 使用完整历史
-		Options:   ollamaOptions,
-		Tools:     ollamaTools,
-		KeepAlive: config.AiProvider.Options.KeepAlive,
-	})
-	if err != nil {
-		return "", err
-	}
-
-	// 更新历史：添加模型回复
-	userHistory = append(userHistory, ai_provider.Message{Role: "assistant", Content: resp.Message.Content})
-
-	// 如果有工具调用
-	if len(resp.Message.ToolCalls) > 0 {
-		for _, c := range resp.Message.ToolCalls {
-			args, err := ai_provider.ParseToolArguments(c.Function.Arguments)
-			if err != nil {
-				args = map[string]any{"_error": err.Error()}
-			}
-
-			out, err := h.mcpCli.CallTool(context.Background(), c.Function.Name, args)
-			if err != nil {
-				out = "tool error: " + err.Error()
-			}
-
-			// 添加工具执行结果到历史
-			userHistory = append(userHistory, ai_provider.Message{
-				Role:     "tool",
-				ToolName: c.Function.Name,
-				Content:  out,
-			})
-			logger.Infof("[tool] %s executed\n", c.Function.Name)
+	window := h.newContextWindow(config.AiProvider.Model)
+
+	// 多轮：模型请求工具 -> 执行 -> 带结果再次调用，直到模型不再请求工具或达到轮数上限
+	limit := toolRoundLimit()
+	var reply string
+	reason := "completed"
+	for round := 1; ; round++ {
+		if round > limit {
+			logger.Warnf("[tool] session %s reached tool round limit %d", sessionID, limit)
+			reason = "tool_round_limit"
+			break
 		}
 
-		// 再次调用模型，传入完整历史（包含工具返回）
-		resp2, err := h.aiProviderCli.Chat(h.ctx, ai_provider.ChatRequest{
-			Model:    config.AiProvider.Model,
-			Messages: userHistory, // 包含工具返回的新历史
-			Options:  ollamaOptions,
-			Tools:    ollamaTools,
+		// 使用完整历史（含本轮已有的工具返回），超出预算时裁剪
+		msgs, err := window.fit(h.ctx, userHistory)
+		if err != nil {
+			return "", "", err
+		}
+		resp, err := h.aiProviderCli.Chat(h.ctx, ai_provider.ChatRequest{
+			Model:     config.AiProvider.Model,
+			Messages:  msgs,
+			Options:   ollamaOptions,
+			Tools:     ollamaTools,
+			KeepAlive: config.AiProvider.Options.KeepAlive,
 		})
 		if err != nil {
-			return "", err
+			return "", "", err
 		}
 
-		// 更新历史：添加最终模型回复
-		userHistory = append(userHistory, ai_provider.Message{Role: "assistant", Content: resp2.Message.Content})
+		// 更新历史：添加模型回复，连同其请求的工具调用一起保存，保证下一轮回放时工具结果有对应的调用
+		reply = resp.Message.Content
+		toolCalls := withToolCallIDs(resp.Message.ToolCalls)
+		userHistory = append(userHistory, ai_provider.Message{Role: "assistant", Content: reply, ToolCalls: toolCalls})
+
+		// 无工具调用，模型已给出最终回答
+		if len(toolCalls) == 0 {
+			break
+		}
 
-		// 保存回 map
-		history[id] = userHistory
+		// 非流式接口没有推送通道，需要审批的调用可通过审批接口查询并处理
+		outcomes := h.runTools(h.ctx, sessionID, ChatOptions{}, round, toolCalls, ollamaToolArgs(toolCalls), func(string, any) error { return nil })
 
-		return resp2.Message.Content, nil
+		// 添加工具执行结果到历史
+		for i, c := range toolCalls {
+			userHistory = append(userHistory, toolMessage(c, outcomes[i]))
+			logger.Infof("[tool round %d] %s executed\n", round, c.Function.Name)
+		}
 	}
 
-	// 无工具调用，直接返回模型回复
-	history[id] = userHistory // 保存更新后的历史
-	return resp.Message.Content, nil
+	// 保存本轮历史
+	if err := h.store.Append(h.ctx, sessionID, userHistory[turnStart:]...); err != nil {
+		return "", "", err
+	}
+	return reply, reason, nil
 }
 
 func (h *Host) StreamChat(
 	ctx context.Context,
-	id int64,
+	sessionID string,
 	userMsg string,
+	opts ChatOptions,
 	emit func(event string, v any) error, // SSE: event 名 + 任意 JSON 数据
 ) error {
-	// 历史
-	hist := history[id]
-	if hist == nil {
-		hist = []ai_provider.Message{}
-	}
-	// 加用户消息
-	hist = append(hist, ai_provider.Message{Role: "user", Content: userMsg})
-
-	tools := h.mcpCli.ConvertToolsToOllama()
-	opts := ai_provider.BuildOptions()
-	// 首次流式：边生成边推，遇到 tool_calls 停止
-	var assistantBuf string
-	var toolCalls []ai_provider.ToolCall
-
-	err := h.aiProviderCli.ChatStream(ctx, ai_provider.ChatRequest{
-		Model:     config.AiProvider.Model,
-		Messages:  hist,
-		Tools:     tools,
-		Options:   opts,
-		KeepAlive: config.AiProvider.Options.KeepAlive,
-	}, func(chunk *ai_provider.ChatResponse) error {
-		// 增量文本
-		if s := chunk.Message.Content; s != "" {
-			assistantBuf += s
-			// 推送到handler层
-			_ = emit(constant.SSEEventDelta, map[string]any{"text": s})
-		}
-		// 工具调用（可能在中途出现）
-		if len(chunk.Message.ToolCalls) > 0 {
-			toolCalls = append(toolCalls, chunk.Message.ToolCalls...)
-			_ = emit(constant.SSEEventStartToolCall, map[string]any{"tool_calls": chunk.Message.ToolCalls})
-			return errno.OllamaInternalStopStream // 提前结束首次流
-		}
-		return nil
-	})
+	ctx, emit, cancel := watchEmit(ctx, emit)
+	defer cancel()
+
+	user, err := h.userMessage(ctx, opts, userMsg)
 	if err != nil {
 		return err
 	}
 
-	// 把模型已生成的片段先落历史
-	if assistantBuf != "" {
-		hist = append(hist, ai_provider.Message{Role: "assistant", Content: assistantBuf})
+	// 历史
+	hist, err := h.loadHistory(ctx, sessionID)
+	if err != nil {
+		return err
 	}
-
-	// 没有工具调用：直接完成
-	if len(toolCalls) == 0 {
-		history[id] = hist
-		_ = emit("done", map[string]any{"reason": "no_tool"})
-		return nil
+	turnStart := len(hist)
+	saveTurn := func() error {
+		return h.store.Append(ctx, sessionID, hist[turnStart:]...)
 	}
+	// 加用户消息
+	hist = append(hist, user)
+
+	tools := h.mcpCli.ConvertToolsToOllama(opts.Tools...)
+	model := opts.model()
+	ollamaOptions := opts.ollamaOptions()
+
+	window := h.newContextWindow(model)
+	limit := toolRoundLimit()
+	round := 0
+	for {
+		round++
+		if round > limit {
+			if err := saveTurn(); err != nil {
+				return err
+			}
+			_ = emit(constant.SSEEventDone, map[string]any{"reason": "tool_round_limit"})
+			return nil
+		}
 
-	// 执行工具
-	for _, tc := range toolCalls {
-		args, err := ai_provider.ParseToolArguments(tc.Function.Arguments)
+		// 一轮流式：边生成边推，遇到 tool_calls 停止
+		var assistantBuf string
+		var toolCalls []ai_provider.ToolCall
+
+		msgs, err := window.fit(ctx, hist)
 		if err != nil {
-			args = map[string]any{"_error": err.Error()}
+			return err
 		}
-
-		_ = emit(constant.SSEEventToolCall, map[string]any{
-			"name": tc.Function.Name,
-			"args": args,
+		err = h.aiProviderCli.ChatStream(ctx, ai_provider.ChatRequest{
+			Model:     model,
+			Messages:  msgs,
+			Tools:     tools,
+			Options:   ollamaOptions,
+			KeepAlive: config.AiProvider.Options.KeepAlive,
+		}, func(chunk *ai_provider.ChatResponse) error {
+			// 增量文本
+			if s := chunk.Message.Content; s != "" {
+				assistantBuf += s
+				// 推送到handler层
+				if err := emit(constant.SSEEventDelta, map[string]any{"text": s}); err != nil {
+					return err // 客户端已断开，停止本轮生成
+				}
+			}
+			// 工具调用（可能在中途出现）
+			if len(chunk.Message.ToolCalls) > 0 {
+				toolCalls = append(toolCalls, chunk.Message.ToolCalls...)
+				return errno.OllamaInternalStopStream // 提前结束本轮流
+			}
+			return nil
 		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return h.cancelTurn(ctx, sessionID, hist[turnStart:], assistantBuf)
+			}
+			return err
+		}
 
-		out, callErr := h.mcpCli.CallTool(ctx, tc.Function.Name, args)
-		if callErr != nil {
-			out = "tool error: " + callErr.Error()
+		// 把模型已生成的片段与请求的工具调用一起落历史
+		toolCalls = withToolCallIDs(toolCalls)
+		if assistantBuf != "" || len(toolCalls) > 0 {
+			hist = append(hist, ai_provider.Message{Role: "assistant", Content: assistantBuf, ToolCalls: toolCalls})
 		}
 
-		// 工具结果给前端
-		_ = emit(constant.SSEEventToolResult, map[string]any{
-			"name":   tc.Function.Name,
-			"result": out,
-		})
+		// 没有工具调用：模型已给出最终回答
+		if len(toolCalls) == 0 {
+			if err := saveTurn(); err != nil {
+				return err
+			}
+			_ = emit(constant.SSEEventDone, map[string]any{"reason": "completed"})
+			return nil
+		}
 
-		// 工具结果落历史
-		hist = append(hist, ai_provider.Message{
-			Role:     "tool",
-			ToolName: tc.Function.Name,
-			Content:  out,
+		_ = emit(constant.SSEEventStartToolCall, map[string]any{
+			"round":      round,
+			"tool_calls": toolCallsPayload(toolCalls),
 		})
+
+		// 执行工具，结果按调用顺序落历史
+		outcomes := h.runTools(ctx, sessionID, opts, round, toolCalls, ollamaToolArgs(toolCalls), emit)
+		for i, tc := range toolCalls {
+			hist = append(hist, toolMessage(tc, outcomes[i]))
+		}
+
+		// 工具执行期间客户端断开：工具调用已被取消，结果均已落历史
+		if ctx.Err() != nil {
+			return h.cancelTurn(ctx, sessionID, hist[turnStart:], "")
+		}
+
+		// 循环进入下一轮：模型会在包含工具结果的上下文上继续生成
 	}
+}
 
-	// 6) 二次流式：带工具结果，让模型给最终回答
-	var finalBuf string
-	err = h.aiProviderCli.ChatStream(ctx, ai_provider.ChatRequest{
-		Model:     config.AiProvider.Model,
-		Messages:  hist,
-		Tools:     tools,
-		Options:   opts,
-		KeepAlive: config.AiProvider.Options.KeepAlive,
-	}, func(chunk *ai_provider.ChatResponse) error {
-		if s := chunk.Message.Content; s != "" {
-			finalBuf += s
-			_ = emit(constant.SSEEventDelta, map[string]any{"text": s})
+// ollamaToolArgs 解析 Ollama 工具调用的参数，解析失败时以 _error 说明原因
+func ollamaToolArgs(calls []ai_provider.ToolCall) []any {
+	out := make([]any, len(calls))
+	for i, c := range calls {
+		args, err := ai_provider.ParseToolArguments(c.Function.Arguments)
+		if err != nil {
+			args = map[string]any{"_error": err.Error()}
 		}
-		return nil
-	})
-	if err != nil {
-		return err
+		out[i] = args
 	}
+	return out
+}
 
-	// 7) 结束收尾：保存历史、发 done
-	if finalBuf != "" {
-		hist = append(hist, ai_provider.Message{Role: "assistant", Content: finalBuf})
+// withToolCallIDs Ollama 返回的工具调用通常不带 id，这里补齐，用于把工具结果关联回对应的调用
+func withToolCallIDs(calls []ai_provider.ToolCall) []ai_provider.ToolCall {
+	for i := range calls {
+		if calls[i].ID == "" {
+			calls[i].ID = "call_" + uuid.NewString()
+		}
+		if calls[i].Type == "" {
+			calls[i].Type = "function"
+		}
 	}
-	history[id] = hist
-	_ = emit(constant.SSEEventDone, map[string]any{"reason": "completed"})
-	return nil
+	return calls
 }
@@ -2,11 +2,15 @@ package host
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
-	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
 	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
 	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
 	openai "github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+	"net/http"
+	"strings"
 )
 
 // 将 OpenAI 的 tool_calls[].function.arguments (string) 解成 map[string]any（与原逻辑一致）
@@ -22,32 +26,158 @@ func parseOpenAIToolArgs(argStr string) map[string]any {
 	return map[string]any{"_": argStr}
 }
 
-const maxToolRounds = 10 // 防御性上限，避免死循环
+// rawToolArguments 将 OpenAI 的字符串参数转为 json.RawMessage 以便落存储；
+// 流式聚合出的参数偶尔不是合法 JSON，此时按 JSON 字符串保存，ParseToolArguments 可兼容两种形式
+func rawToolArguments(argStr string) json.RawMessage {
+	if argStr == "" {
+		return json.RawMessage("{}")
+	}
+	if json.Valid([]byte(argStr)) {
+		return json.RawMessage(argStr)
+	}
+	b, _ := json.Marshal(argStr)
+	return b
+}
+
+// toOpenAIMessages 将存储中的历史（可分多段传入）转换为 OpenAI Chat Completions 消息
+// tool 消息只能是文本，工具返回的图片在这一批 tool 消息之后以一条 user 消息的图片 parts 发送；
+// 传入的消息应先经过 contextWindow.fit，模型不支持图片时其中的图片已换成文本占位
+func toOpenAIMessages(parts ...[]ai_provider.Message) []openai.ChatCompletionMessageParamUnion {
+	var out []openai.ChatCompletionMessageParamUnion
+	var toolImages []openai.ChatCompletionContentPartUnionParam
+	flushToolImages := func() {
+		if len(toolImages) == 0 {
+			return
+		}
+		contentParts := append([]openai.ChatCompletionContentPartUnionParam{openai.TextContentPart("以下是工具返回的图片")}, toolImages...)
+		out = append(out, openai.UserMessage(contentParts))
+		toolImages = nil
+	}
+	for _, msgs := range parts {
+		for _, m := range msgs {
+			if m.Role != "tool" {
+				flushToolImages()
+			}
+			switch m.Role {
+			case "system":
+				out = append(out, openai.SystemMessage(m.Content))
+			case "user":
+				if len(m.Images) == 0 {
+					out = append(out, openai.UserMessage(m.Content))
+					continue
+				}
+				// 带图片的消息以 content parts 发送，图片使用 data URI
+				contentParts := []openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(m.Content)}
+				for _, img := range m.Images {
+					contentParts = append(contentParts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+						URL: imageDataURI(img),
+					}))
+				}
+				out = append(out, openai.UserMessage(contentParts))
+			case "assistant":
+				if len(m.ToolCalls) == 0 {
+					// 被取消且没有生成任何内容的轮次只作记录，不发给模型
+					if m.Content != "" {
+						out = append(out, openai.AssistantMessage(m.Content))
+					}
+					continue
+				}
+				calls := make([]openai.ChatCompletionMessageToolCallUnionParam, 0, len(m.ToolCalls))
+				for _, tc := range m.ToolCalls {
+					calls = append(calls, openai.ChatCompletionMessageToolCallUnionParam{
+						OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+							ID:   tc.ID,
+							Type: "function",
+							Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+								Name:      tc.Function.Name,
+								Arguments: tc.Function.ArgumentsString(),
+							},
+						},
+					})
+				}
+				assistant := openai.ChatCompletionAssistantMessageParam{
+					Role:      "assistant",
+					ToolCalls: calls,
+				}
+				if m.Content != "" {
+					assistant.Content.OfString = openai.String(m.Content)
+				}
+				out = append(out, openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant})
+			case "tool":
+				out = append(out, openai.ToolMessage(m.Content, m.ToolCallID))
+				for _, img := range m.Images {
+					toolImages = append(toolImages, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+						URL: imageDataURI(img),
+					}))
+				}
+			}
+		}
+	}
+	flushToolImages()
+	return out
+}
+
+// imageDataURI 将 base64 图片转为 data URI，MIME 类型根据内容推断
+func imageDataURI(b64 string) string {
+	head, _ := base64.StdEncoding.DecodeString(b64[:min(len(b64), 64)])
+	return "data:" + http.DetectContentType(head) + ";base64," + b64
+}
 
 func (h *Host) StreamChatOpenAI(
 	ctx context.Context,
-	id int64,
+	sessionID string,
 	userMsg string,
+	opts ChatOptions,
 	emit func(event string, v any) error,
 ) error {
-	// 历史（OpenAI）
-	hist := historyOpenAI[id]
-	if hist == nil {
-		hist = []openai.ChatCompletionMessageParamUnion{}
+	ctx, emit, cancel := watchEmit(ctx, emit)
+	defer cancel()
+
+	user, err := h.userMessage(ctx, opts, userMsg)
+	if err != nil {
+		return err
+	}
+
+	// 历史（存储中为 ai_provider.Message，发给模型前转换为 OpenAI 消息）
+	past, err := h.loadHistory(ctx, sessionID)
+	if err != nil {
+		return err
 	}
-	// 用户消息
-	hist = append(hist, openai.UserMessage(userMsg))
 
+	// 本轮新增的消息，结束时统一追加到存储
+	turn, reason, partial, err := h.openAIToolLoop(ctx, sessionID, past, []ai_provider.Message{user}, opts, emit)
+	if err != nil {
+		if ctx.Err() != nil {
+			return h.cancelTurn(ctx, sessionID, turn, partial)
+		}
+		return err
+	}
+	if err := h.store.Append(ctx, sessionID, turn...); err != nil {
+		return err
+	}
+	_ = emit(constant.SSEEventDone, map[string]any{"reason": reason})
+	return nil
+}
+
+// openAIToolLoop 在 past 之上进行多轮 生成 -> 执行工具 -> 带结果继续生成，返回本轮新增的消息（以 turn 开头）与结束原因：
+// completed | tool_round_limit | no_tool_details；被取消时 partial 为最后一次生成中已产生的回复
+func (h *Host) openAIToolLoop(
+	ctx context.Context,
+	sessionID string,
+	past []ai_provider.Message,
+	turn []ai_provider.Message,
+	opts ChatOptions,
+	emit func(event string, v any) error,
+) ([]ai_provider.Message, string, string, error) {
 	// 工具（OpenAI 版）
-	tools := h.mcpCli.ConvertToolsToOpenAI()
-
-	round := 0
-	for {
-		round++
-		if round > maxToolRounds {
-			historyOpenAI[id] = hist
-			_ = emit(constant.SSEEventDone, map[string]any{"reason": "tool_round_limit"})
-			return nil
+	tools := h.mcpCli.ConvertToolsToOpenAI(opts.Tools...)
+
+	model := opts.model()
+	window := h.newContextWindow(model)
+	limit := toolRoundLimit()
+	for round := 1; ; round++ {
+		if round > limit {
+			return turn, "tool_round_limit", "", nil
 		}
 
 		// 一轮生成：边流边推，若需要工具则中断本轮
@@ -55,106 +185,185 @@ func (h *Host) StreamChatOpenAI(
 		var acc openai.ChatCompletionAccumulator
 		var needTools bool
 
-		err := h.aiProviderCli.ChatStreamOpenAI(ctx, openai.ChatCompletionNewParams{
-			Model:    openai.ChatModel(config.AiProvider.Model),
-			Messages: hist,
+		msgs, err := window.fit(ctx, past, turn)
+		if err != nil {
+			return turn, "", "", err
+		}
+		params := openai.ChatCompletionNewParams{
+			Model:    openai.ChatModel(model),
+			Messages: toOpenAIMessages(msgs),
 			Tools:    tools,
-		}, func(chunk *openai.ChatCompletionChunk) error {
+		}
+		if opts.Temperature != nil {
+			params.Temperature = openai.Float(*opts.Temperature)
+		}
+		err = h.aiProviderCli.ChatStreamOpenAI(ctx, params, func(chunk *openai.ChatCompletionChunk) error {
 			acc.AddChunk(*chunk)
 			if len(chunk.Choices) > 0 {
 				if s := chunk.Choices[0].Delta.Content; s != "" {
 					assistantBuf += s
-					_ = emit(constant.SSEEventDelta, map[string]any{"text": s})
+					if err := emit(constant.SSEEventDelta, map[string]any{"text": s}); err != nil {
+						return err // 客户端已断开，停止本轮生成
+					}
 				}
 				// 工具调用结束标志（OpenAI：最后一帧 finish_reason = "tool_calls"）
 				if chunk.Choices[0].FinishReason == "tool_calls" {
 					needTools = true
-					if len(acc.Choices) > 0 && len(acc.Choices[0].Message.ToolCalls) > 0 {
-						_ = emit(constant.SSEEventStartToolCall, map[string]any{
-							"tool_calls": acc.Choices[0].Message.ToolCalls,
-							"round":      round,
-						})
-					}
 					return errno.OllamaInternalStopStream
 				}
 			}
 			return nil
 		})
 		if err != nil {
-			return err
-		}
-
-		// 把已产生的 assistant 文本落历史
-		if assistantBuf != "" && !needTools {
-			hist = append(hist, openai.AssistantMessage(assistantBuf))
+			return turn, "", assistantBuf, err
 		}
 
 		// 如果本轮不需要工具，说明模型已经给出最终答案
 		if !needTools {
-			historyOpenAI[id] = hist
-			_ = emit(constant.SSEEventDone, map[string]any{"reason": "completed"})
-			return nil
+			if assistantBuf != "" {
+				turn = append(turn, ai_provider.Message{Role: "assistant", Content: assistantBuf})
+			}
+			return turn, "completed", "", nil
 		}
 
 		// 执行（可能多个）工具调用，然后将每个工具结果以 ToolMessage 落历史
 		if len(acc.Choices) == 0 || len(acc.Choices[0].Message.ToolCalls) == 0 {
 			// 偶发兜底：标记需要工具但没聚合到（理论上不会发生）
-			historyOpenAI[id] = hist
-			_ = emit(constant.SSEEventDone, map[string]any{"reason": "no_tool_details"})
-			return nil
+			return turn, "no_tool_details", "", nil
 		}
 
-		toolCallsParam := make([]openai.ChatCompletionMessageToolCallUnionParam, 0, len(acc.Choices[0].Message.ToolCalls))
+		toolCalls := make([]ai_provider.ToolCall, 0, len(acc.Choices[0].Message.ToolCalls))
 		for _, tc := range acc.Choices[0].Message.ToolCalls {
-			toolCallsParam = append(toolCallsParam, openai.ChatCompletionMessageToolCallUnionParam{
-				OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
-					ID:   tc.ID,
-					Type: "function",
-					Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
-						Name:      tc.Function.Name,
-						Arguments: tc.Function.Arguments, // 注意：这里是字符串
-					},
+			toolCalls = append(toolCalls, ai_provider.ToolCall{
+				ID:   tc.ID,
+				Type: "function",
+				Function: ai_provider.ToolFunction{
+					Name:      tc.Function.Name,
+					Arguments: rawToolArguments(tc.Function.Arguments), // 注意：OpenAI 这里是字符串
 				},
 			})
 		}
 		// 根据openAI规范，tool_call前需要一条assistantMsg
-		assistantWithCalls := openai.ChatCompletionAssistantMessageParam{
-			Role:      "assistant",
-			ToolCalls: toolCallsParam,
+		turn = append(turn, ai_provider.Message{Role: "assistant", ToolCalls: toolCalls})
+		_ = emit(constant.SSEEventStartToolCall, map[string]any{
+			"round":      round,
+			"tool_calls": toolCallsPayload(toolCalls),
+		})
+
+		// OpenAI 的 arguments 是字符串，需要解成 map[string]any
+		args := make([]any, len(toolCalls))
+		for i, tc := range toolCalls {
+			var m map[string]any
+			if err := json.Unmarshal([]byte(tc.Function.ArgumentsString()), &m); err != nil {
+				m = map[string]any{"_parse_error": err.Error(), "_raw": tc.Function.ArgumentsString()}
+			}
+			args[i] = m
 		}
-		hist = append(hist, openai.ChatCompletionMessageParamUnion{OfAssistant: &assistantWithCalls})
 
-		for _, tc := range acc.Choices[0].Message.ToolCalls {
-			name := tc.Function.Name
+		outcomes := h.runTools(ctx, sessionID, opts, round, toolCalls, args, emit)
+		for i, tc := range toolCalls {
+			// 工具结果回模型（重要）：OpenAI 规范用 ToolMessage，必须带 tool_call_id，且按调用顺序排列
+			turn = append(turn, toolMessage(tc, outcomes[i]))
+		}
 
-			// OpenAI 的 arguments 是字符串，需要解成 map[string]any
-			var args map[string]any
-			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-				args = map[string]any{"_parse_error": err.Error(), "_raw": tc.Function.Arguments}
-			}
+		// 工具执行期间客户端断开：工具调用已被取消，结果均已落历史
+		if ctx.Err() != nil {
+			return turn, "", "", ctx.Err()
+		}
 
-			_ = emit(constant.SSEEventToolCall, map[string]any{
-				"round": round,
-				"name":  name,
-				"args":  args,
-			})
+		// 循环进入下一轮：模型会在新的上下文（含工具结果）上继续生成
+	}
+}
 
-			out, callErr := h.mcpCli.CallTool(ctx, name, args)
-			if callErr != nil {
-				out = "tool error: " + callErr.Error()
-			}
+// ChatCompletion 无状态对话（OpenAI 兼容接口使用）：以调用方传入的 msgs 作为完整上下文执行同样的工具循环，
+// 不读写会话存储；回复通过 emit 推送，返回最终回复与结束原因
+func (h *Host) ChatCompletion(
+	ctx context.Context,
+	msgs []ai_provider.Message,
+	opts ChatOptions,
+	emit func(event string, v any) error,
+) (string, string, error) {
+	ctx, emit, cancel := watchEmit(ctx, emit)
+	defer cancel()
 
-			_ = emit(constant.SSEEventToolResult, map[string]any{
-				"round":  round,
-				"name":   name,
-				"result": out,
-			})
+	turn, reason, _, err := h.openAIToolLoop(ctx, "", msgs, nil, opts, emit)
+	if err != nil {
+		return "", "", err
+	}
+	var reply string
+	if n := len(turn); n > 0 && turn[n-1].Role == "assistant" {
+		reply = turn[n-1].Content
+	}
+	return reply, reason, nil
+}
 
-			// 工具结果回模型（重要）：OpenAI 规范用 ToolMessage，必须带 tool_call_id
-			hist = append(hist, openai.ToolMessage(out, tc.ID))
-			//logger.Infof("[tool round %d] %s executed", round, name)
+// FromOpenAIMessages 将 OpenAI Chat Completions 请求中的消息转换为 ai_provider.Message（toOpenAIMessages 的逆过程），
+// developer 消息按 system 处理，图片仅支持 base64 data URI
+func FromOpenAIMessages(msgs []openai.ChatCompletionMessageParamUnion) ([]ai_provider.Message, error) {
+	out := make([]ai_provider.Message, 0, len(msgs))
+	for _, m := range msgs {
+		switch {
+		case m.OfSystem != nil:
+			out = append(out, ai_provider.Message{Role: "system", Content: textContent(m.OfSystem.Content.OfString, m.OfSystem.Content.OfArrayOfContentParts)})
+		case m.OfDeveloper != nil:
+			out = append(out, ai_provider.Message{Role: "system", Content: textContent(m.OfDeveloper.Content.OfString, m.OfDeveloper.Content.OfArrayOfContentParts)})
+		case m.OfUser != nil:
+			msg := ai_provider.Message{Role: "user", Content: m.OfUser.Content.OfString.Value}
+			for _, p := range m.OfUser.Content.OfArrayOfContentParts {
+				switch {
+				case p.OfText != nil:
+					msg.Content += p.OfText.Text
+				case p.OfImageURL != nil:
+					img, ok := strings.CutPrefix(p.OfImageURL.ImageURL.URL, "data:")
+					_, b64, found := strings.Cut(img, ";base64,")
+					if !ok || !found {
+						return nil, errno.Errorf(errno.ParamFormatCode, "图片仅支持 base64 data URI")
+					}
+					msg.Images = append(msg.Images, b64)
+				default:
+					return nil, errno.Errorf(errno.ParamTypeCode, "不支持的消息内容类型")
+				}
+			}
+			out = append(out, msg)
+		case m.OfAssistant != nil:
+			msg := ai_provider.Message{Role: "assistant", Content: m.OfAssistant.Content.OfString.Value}
+			for _, p := range m.OfAssistant.Content.OfArrayOfContentParts {
+				if p.OfText != nil {
+					msg.Content += p.OfText.Text
+				}
+			}
+			for _, tc := range m.OfAssistant.ToolCalls {
+				if tc.OfFunction == nil {
+					continue
+				}
+				msg.ToolCalls = append(msg.ToolCalls, ai_provider.ToolCall{
+					ID:   tc.OfFunction.ID,
+					Type: "function",
+					Function: ai_provider.ToolFunction{
+						Name:      tc.OfFunction.Function.Name,
+						Arguments: rawToolArguments(tc.OfFunction.Function.Arguments),
+					},
+				})
+			}
+			out = append(out, msg)
+		case m.OfTool != nil:
+			out = append(out, ai_provider.Message{
+				Role:       "tool",
+				ToolCallID: m.OfTool.ToolCallID,
+				Content:    textContent(m.OfTool.Content.OfString, m.OfTool.Content.OfArrayOfContentParts),
+			})
+		default:
+			return nil, errno.Errorf(errno.ParamTypeCode, "不支持的消息角色")
 		}
+	}
+	return out, nil
+}
 
-		// 循环进入下一轮：模型会在新的上下文（含工具结果）上继续生成
+// textContent 拼接纯文本消息内容（字符串或 text parts）
+func textContent(s param.Opt[string], parts []openai.ChatCompletionContentPartTextParam) string {
+	text := s.Value
+	for _, p := range parts {
+		text += p.Text
 	}
+	return text
 }
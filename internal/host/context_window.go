@@ -0,0 +1,223 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	openai "github.com/openai/openai-go/v2"
+	"strings"
+	"unicode/utf8"
+)
+
+const summarizePrompt = "你是对话摘要助手。请将下面的历史对话压缩成简洁的摘要，保留用户的目标、已确认的事实、工具调用得到的关键结果和尚未完成的事项，不要编造内容。"
+
+// contextWindow 控制每次发给模型的上下文不超过预算（按估算的 token 数）：
+//  1. 未超预算时原样发送
+//  2. 超预算时先截断过长的工具输出
+//  3. 仍超预算时把较早的对话交给模型总结成一条 system 摘要，只原样保留最近几轮
+//  4. 保留的最近几轮本身仍超预算时，按剩余预算进一步截断其中的工具输出，仍超出则返回 errno.ContextTooLong
+//
+// 只影响发给模型的消息，存储中的完整记录不受影响。每次对话创建一个，摘要在本次对话内复用
+type contextWindow struct {
+	h             *Host
+	model         string
+	budget        int
+	maxToolTokens int
+	keepTurns     int
+
+	summarized int    // 已摘要的消息条数
+	summary    string // 对应的摘要内容
+}
+
+func (h *Host) newContextWindow(model string) *contextWindow {
+	w := &contextWindow{
+		h:             h,
+		model:         model,
+		maxToolTokens: constant.ContextDefaultMaxToolOutputTokens,
+		keepTurns:     constant.ContextDefaultKeepTurns,
+	}
+	if config.AiProvider == nil {
+		return w
+	}
+	c := config.AiProvider.Context
+	w.budget = c.Budget
+	for _, m := range c.Models {
+		if m.Name == model {
+			w.budget = m.Budget
+			break
+		}
+	}
+	if c.MaxToolOutputTokens > 0 {
+		w.maxToolTokens = c.MaxToolOutputTokens
+	}
+	if c.KeepTurns > 0 {
+		w.keepTurns = c.KeepTurns
+	}
+	return w
+}
+
+// fit 返回裁剪到预算内、可直接发给模型的消息（可分多段传入），不修改入参；
+// 模型不支持图片时图片先换成文本占位（见 visionMessages）再估算
+func (w *contextWindow) fit(ctx context.Context, parts ...[]ai_provider.Message) ([]ai_provider.Message, error) {
+	var msgs []ai_provider.Message
+	for _, p := range parts {
+		msgs = append(msgs, p...)
+	}
+	msgs = visionMessages(w.model, msgs)
+	if w.budget <= 0 || estimateMessages(msgs) <= w.budget {
+		return msgs, nil
+	}
+
+	// 1) 截断过长的工具输出
+	for i, m := range msgs {
+		if m.Role == "tool" && estimateTokens(m.Content) > w.maxToolTokens {
+			msgs[i].Content = truncateTokens(m.Content, w.maxToolTokens)
+		}
+	}
+	if estimateMessages(msgs) <= w.budget {
+		return msgs, nil
+	}
+
+	// 2) 总结较早的对话：开头的 system 消息保留，最近 keepTurns 轮原样保留
+	head := 0
+	for head < len(msgs) && msgs[head].Role == "system" {
+		head++
+	}
+	body := msgs[head:]
+	out := msgs
+	if recent := turnStartIndex(body, w.keepTurns); recent > 0 {
+		older := body[:recent]
+		out = append([]ai_provider.Message{}, msgs[:head]...)
+		if summary, err := w.summarize(ctx, older); err != nil {
+			// 摘要失败时直接丢弃较早的对话，保证请求仍能发出
+			logger.Warnf("[context] summarize %d messages failed, dropping them: %v", len(older), err)
+		} else {
+			out = append(out, ai_provider.Message{Role: "system", Content: "以下是此前对话的摘要：\n" + summary})
+		}
+		out = append(out, body[recent:]...)
+		if estimateMessages(out) <= w.budget {
+			return out, nil
+		}
+	}
+
+	// 3) 保留的部分仍超预算（如最近一轮的工具输出很大）：剩余预算平均分给其中的工具输出
+	return w.shrinkToolOutputs(out)
+}
+
+// shrinkToolOutputs 把工具输出截断到平均分得的剩余预算内，其余消息本身已超出预算时返回 errno.ContextTooLong
+func (w *contextWindow) shrinkToolOutputs(msgs []ai_provider.Message) ([]ai_provider.Message, error) {
+	var tools []int
+	rest := 0
+	for i, m := range msgs {
+		if m.Role == "tool" {
+			tools = append(tools, i)
+			rest += estimateMessages([]ai_provider.Message{{Images: m.Images}})
+			continue
+		}
+		rest += estimateMessages(msgs[i : i+1])
+	}
+	// 每条截断后的输出还会附带截断说明，按 truncateNoteTokens 预留
+	per := 0
+	if len(tools) > 0 {
+		per = (w.budget-rest)/len(tools) - truncateNoteTokens
+	}
+	if per <= 0 {
+		logger.Warnf("[context] %d tokens over budget %d after truncating tool outputs", estimateMessages(msgs), w.budget)
+		return nil, errno.ContextTooLong
+	}
+	for _, i := range tools {
+		if estimateTokens(msgs[i].Content) > per {
+			msgs[i].Content = truncateTokens(msgs[i].Content, per)
+		}
+	}
+	return msgs, nil
+}
+
+func (w *contextWindow) summarize(ctx context.Context, msgs []ai_provider.Message) (string, error) {
+	if w.summary != "" && w.summarized == len(msgs) {
+		return w.summary, nil
+	}
+	resp, err := w.h.aiProviderCli.ChatOpenAI(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModel(w.model),
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(summarizePrompt),
+			openai.UserMessage(renderTranscript(msgs)),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	w.summarized, w.summary = len(msgs), resp.Choices[0].Message.Content
+	return w.summary, nil
+}
+
+// renderTranscript 将消息渲染为纯文本对话记录，供模型总结
+func renderTranscript(msgs []ai_provider.Message) string {
+	var sb strings.Builder
+	for _, m := range msgs {
+		switch m.Role {
+		case "tool":
+			fmt.Fprintf(&sb, "[工具 %s 返回] %s\n", m.ToolName, m.Content)
+		default:
+			if m.Content != "" {
+				fmt.Fprintf(&sb, "[%s] %s\n", m.Role, m.Content)
+			}
+			for _, tc := range m.ToolCalls {
+				fmt.Fprintf(&sb, "[%s 调用工具] %s(%s)\n", m.Role, tc.Function.Name, tc.Function.ArgumentsString())
+			}
+		}
+	}
+	return sb.String()
+}
+
+// estimateMessages 估算一组消息的 token 数
+func estimateMessages(msgs []ai_provider.Message) int {
+	n := 0
+	for _, m := range msgs {
+		n += 4 + estimateTokens(m.Content) // 每条消息的角色等固定开销按 4 计
+		n += len(m.Images) * constant.ContextImageTokens
+		for _, tc := range m.ToolCalls {
+			n += estimateTokens(tc.Function.Name) + estimateTokens(string(tc.Function.Arguments))
+		}
+	}
+	return n
+}
+
+// estimateTokens 粗略估算 token 数：ASCII 约 4 个字符一个 token，其他字符（如中文）按一个字符一个 token
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// truncateNoteTokens truncateTokens 附加的截断说明约占的 token 数
+const truncateNoteTokens = 24
+
+// truncateTokens 截断到约 max 个 token，并注明截断
+func truncateTokens(s string, max int) string {
+	n := 0
+	for i, r := range s {
+		if r < utf8.RuneSelf {
+			n++
+		} else {
+			n += 4
+		}
+		if n > max*4 {
+			return s[:i] + fmt.Sprintf("\n...[内容过长已截断，原长度约 %d tokens]", estimateTokens(s))
+		}
+	}
+	return s
+}
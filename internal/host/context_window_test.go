@@ -0,0 +1,103 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+)
+
+func TestContextWindow(t *testing.T) {
+	Convey("Test contextWindow", t, func() {
+		// 模拟 OpenAI 兼容接口，记录摘要请求使用的模型
+		models := make(chan string, 1)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Model string `json:"model"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			models <- req.Model
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","created":0,"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"summary"},"finish_reason":"stop"}]}`))
+		}))
+		Reset(ts.Close)
+
+		cfg := new(config.Config)
+		cfg.AiProvider.Mode = constant.AiProviderModeRemote
+		cfg.AiProvider.Model = "deepseek-chat"
+		cfg.AiProvider.Remote.BaseURL = ts.URL
+		cfg.AiProvider.Context.Budget = 300
+		cfg.AiProvider.Context.KeepTurns = 1
+		config.AiProvider = &cfg.AiProvider
+		Reset(func() { config.AiProvider = nil })
+
+		h := &Host{aiProviderCli: ai_provider.NewAiProviderClient()}
+		ctx := context.Background()
+
+		Convey("summarize with the window's model", func() {
+			w := h.newContextWindow("qwen-max")
+			msgs, err := w.fit(ctx, []ai_provider.Message{
+				{Role: "user", Content: strings.Repeat("a", 2000)},
+				{Role: "assistant", Content: "ok"},
+				{Role: "user", Content: "hi"},
+			})
+			So(err, ShouldBeNil)
+			So(<-models, ShouldEqual, "qwen-max")
+			So(msgs, ShouldHaveLength, 2)
+			So(msgs[0].Content, ShouldEndWith, "summary")
+			So(msgs[1].Content, ShouldEqual, "hi")
+		})
+
+		Convey("large tool outputs of the newest turn are truncated", func() {
+			w := h.newContextWindow("qwen-max")
+			turn := []ai_provider.Message{
+				{Role: "user", Content: "list files"},
+				{Role: "assistant", ToolCalls: []ai_provider.ToolCall{{ID: "1"}, {ID: "2"}}},
+				{Role: "tool", ToolCallID: "1", Content: strings.Repeat("a", 8000)},
+				{Role: "tool", ToolCallID: "2", Content: strings.Repeat("b", 8000)},
+			}
+			msgs, err := w.fit(ctx, turn)
+			So(err, ShouldBeNil)
+			So(estimateMessages(msgs), ShouldBeLessThanOrEqualTo, 300)
+			So(msgs[2].Content, ShouldContainSubstring, "内容过长已截断")
+			So(msgs[3].Content, ShouldContainSubstring, "内容过长已截断")
+			// 不修改入参
+			So(turn[2].Content, ShouldHaveLength, 8000)
+		})
+
+		Convey("newest turn over budget without tool outputs", func() {
+			w := h.newContextWindow("qwen-max")
+			_, err := w.fit(ctx, []ai_provider.Message{{Role: "user", Content: strings.Repeat("a", 4000)}})
+			So(err, ShouldEqual, errno.ContextTooLong)
+		})
+
+		Convey("images", func() {
+			img := []ai_provider.Message{{Role: "user", Content: "look", Images: []string{png}}}
+			So(estimateMessages(img), ShouldEqual, 4+1+constant.ContextImageTokens)
+
+			cfg.AiProvider.Context.Budget = 0
+			msgs, err := h.newContextWindow("qwen-max").fit(ctx, img)
+			So(err, ShouldBeNil)
+			So(msgs[0].Images, ShouldBeNil)
+
+			cfg.AiProvider.VisionModels = []string{"qwen-vl"}
+			msgs, err = h.newContextWindow("qwen-vl").fit(ctx, img)
+			So(err, ShouldBeNil)
+			So(msgs[0].Images, ShouldResemble, []string{png})
+
+			// 图片计入预算
+			cfg.AiProvider.Context.Budget = 300
+			_, err = h.newContextWindow("qwen-vl").fit(ctx, img)
+			So(err, ShouldEqual, errno.ContextTooLong)
+		})
+	})
+}
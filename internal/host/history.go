@@ -0,0 +1,55 @@
+package host
+
+import (
+	"context"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+)
+
+// loadHistory 读取本次请求发给模型的上下文，按 cli 配置处理：
+// - history=false 时不带历史，每次请求独立
+// - max_turns>0 时只保留最近 max_turns 轮（以 user 消息为一轮的开始，不会拆开工具调用与其结果）
+// - system_prompt 非空时放在最前面
+// 存储中的完整记录不受影响，system 消息也不会写回存储
+func (h *Host) loadHistory(ctx context.Context, sessionID string) ([]ai_provider.Message, error) {
+	var msgs []ai_provider.Message
+	if config.CLI == nil || config.CLI.History {
+		past, err := h.store.Get(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		msgs = past
+		if config.CLI != nil {
+			msgs = lastTurns(past, config.CLI.MaxTurns)
+		}
+	}
+
+	out := make([]ai_provider.Message, 0, len(msgs)+1)
+	if config.CLI != nil && config.CLI.SystemPrompt != "" {
+		out = append(out, ai_provider.Message{Role: "system", Content: config.CLI.SystemPrompt})
+	}
+	return append(out, msgs...), nil
+}
+
+// lastTurns 保留最近 n 轮对话，n<=0 时不裁剪
+func lastTurns(msgs []ai_provider.Message, n int) []ai_provider.Message {
+	return msgs[turnStartIndex(msgs, n):]
+}
+
+// turnStartIndex 返回最近 n 轮对话的起始下标（以 user 消息为一轮的开始），n<=0 或不足 n 轮时返回 0
+func turnStartIndex(msgs []ai_provider.Message, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	turns := 0
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != "user" {
+			continue
+		}
+		turns++
+		if turns == n {
+			return i
+		}
+	}
+	return 0
+}
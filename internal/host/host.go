@@ -2,20 +2,25 @@ package host
 
 import (
 	"context"
+	"github.com/FantasyRL/go-mcp-demo/config"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/conversation_store"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
-	"github.com/openai/openai-go/v2"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_approval"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_cache"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"sync"
 )
 
-// 简单的内存存储用户对话历史
-var history = make(map[int64][]ai_provider.Message)
-var historyOpenAI = make(map[int64][]openai.ChatCompletionMessageParamUnion)
-
 type Host struct {
 	ctx           context.Context
-	mcpCli        *mcp_client.MCPClient
+	mcpCli        *mcp_client.Pool
 	aiProviderCli *ai_provider.Client
+	store         conversation_store.ConversationStore
+	approvals     *tool_approval.Registry
+	toolCache     *tool_cache.Cache
 }
 
 func NewHost(ctx context.Context, clientSet *base.ClientSet) *Host {
@@ -23,5 +28,144 @@ func NewHost(ctx context.Context, clientSet *base.ClientSet) *Host {
 		ctx:           ctx,
 		mcpCli:        clientSet.MCPCli,
 		aiProviderCli: clientSet.AiProviderCli,
+		store:         clientSet.ConversationStore,
+		approvals:     clientSet.ToolApprovals,
+		toolCache:     clientSet.ToolCache,
+	}
+}
+
+// toolRoundLimit 单次对话最多进行的工具调用轮数
+func toolRoundLimit() int {
+	if config.AiProvider != nil && config.AiProvider.ToolRoundLimit > 0 {
+		return config.AiProvider.ToolRoundLimit
+	}
+	return constant.AiProviderDefaultToolRoundLimit
+}
+
+// toolParallelism 同一轮中最多同时执行的工具调用数
+func toolParallelism() int {
+	if config.AiProvider != nil && config.AiProvider.ToolParallelism > 0 {
+		return config.AiProvider.ToolParallelism
+	}
+	return constant.AiProviderDefaultToolParallelism
+}
+
+// runTools 执行模型在同一轮中请求的全部工具调用：最多 toolParallelism 个同时执行，
+// 每个调用开始时推送 tool_call、完成时推送 tool_result；返回的结果与 calls 顺序一致
+func (h *Host) runTools(
+	ctx context.Context,
+	sessionID string,
+	opts ChatOptions,
+	round int,
+	calls []ai_provider.ToolCall,
+	args []any,
+	emit func(event string, v any) error,
+) []toolOutcome {
+	// 多个调用并发推送事件，串行化以保证事件 id 与写出顺序一致
+	var emitMu sync.Mutex
+	push := func(event string, v any) error {
+		emitMu.Lock()
+		defer emitMu.Unlock()
+		return emit(event, v)
 	}
+
+	outcomes := make([]toolOutcome, len(calls))
+	sem := make(chan struct{}, toolParallelism())
+	var wg sync.WaitGroup
+	for i, tc := range calls {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			_ = push(constant.SSEEventToolCall, map[string]any{
+				"round": round,
+				"id":    tc.ID,
+				"name":  tc.Function.Name,
+				"args":  args[i],
+			})
+			outcomes[i] = h.runTool(ctx, sessionID, opts, round, tc, args[i], push)
+			_ = push(constant.SSEEventToolResult, toolResultPayload(round, tc, outcomes[i]))
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// callTool 调用 MCP 工具：不在本次对话白名单内或被策略禁止的工具直接拒绝；
+// 需要审批时推送 approval_required 事件并等待用户决定，超时视为拒绝；
+// 可缓存的工具命中缓存时直接返回缓存结果（cached 为 true），执行写类型的工具后清空会话缓存
+func (h *Host) callTool(
+	ctx context.Context,
+	sessionID string,
+	opts ChatOptions,
+	round int,
+	call ai_provider.ToolCall,
+	args any,
+	emit func(event string, v any) error,
+) (res *mcp_client.ToolResult, cached bool, err error) {
+	name := call.Function.Name
+	if !opts.toolAllowed(name) {
+		return nil, false, errno.ToolDenied.WithMessage("工具 " + name + " 不在本次对话允许的范围内")
+	}
+	switch opts.toolPolicy(name) {
+	case constant.ToolPolicyAuto:
+	case constant.ToolPolicyRequireApproval:
+		// 无会话的对话（如 OpenAI 兼容接口）没有审批途径
+		if sessionID == "" || h.approvals == nil {
+			return nil, false, errno.ToolDenied.WithMessage("工具 " + name + " 需要审批，当前对话无法审批")
+		}
+		approved := h.approvals.Wait(ctx, sessionID, tool_approval.Request{
+			ID:        call.ID,
+			Name:      name,
+			Arguments: call.Function.ArgumentsString(),
+		}, func(req tool_approval.Request) {
+			_ = emit(constant.SSEEventApprovalRequired, map[string]any{
+				"round":      round,
+				"id":         req.ID,
+				"name":       req.Name,
+				"args":       args,
+				"expires_at": req.ExpiresAt.UnixMilli(),
+			})
+		})
+		if !approved {
+			return nil, false, errno.ToolDenied.WithMessage("工具 " + name + " 的调用被拒绝或审批超时")
+		}
+	default:
+		return nil, false, errno.ToolDenied.WithMessage("工具 " + name + " 已被策略禁止调用")
+	}
+
+	ttl, write := h.toolCachePolicy(sessionID, name)
+	var key string
+	var gen uint64
+	if ttl > 0 {
+		key = tool_cache.Key(name, args)
+		if res, gen, cached = h.toolCache.Get(sessionID, key); cached {
+			return res, true, nil
+		}
+	}
+	defer func() {
+		// 幂等的写类型工具先清空会话缓存，再以清空后的 gen 缓存自己的结果
+		if write {
+			gen = h.toolCache.Invalidate(sessionID)
+		}
+		if ttl > 0 && err == nil && !res.IsError {
+			h.toolCache.Set(sessionID, key, res, ttl, gen)
+		}
+	}()
+
+	// 工具的进度通知转为本轮的 tool_progress 事件
+	res, err = h.mcpCli.CallTool(ctx, name, args, func(p mcp_client.Progress) {
+		_ = emit(constant.SSEEventToolProgress, map[string]any{
+			"round":    round,
+			"id":       call.ID,
+			"name":     name,
+			"progress": p.Progress,
+			"total":    p.Total,
+			"message":  p.Message,
+		})
+	})
+	return res, false, err
 }
@@ -0,0 +1,28 @@
+package host
+
+import (
+	"context"
+	"net/http/httptest"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+)
+
+// newTestHost 返回连接到 s 的 Host，s 以 Streamable HTTP 方式启动，Convey 结束时关闭
+func newTestHost(s *mcpserver.MCPServer) *Host {
+	ts := httptest.NewServer(mcpserver.NewStreamableHTTPServer(s))
+	cfg := config.MCPServerConfig{Name: "test", Transport: constant.MCPTransportHTTP}
+	cfg.HTTP.BaseURL = ts.URL
+	pool, err := mcp_client.NewPool([]config.MCPServerConfig{cfg}, false)
+	So(err, ShouldBeNil)
+	Reset(func() {
+		pool.Close()
+		ts.CloseClientConnections()
+		ts.Close()
+	})
+	return &Host{ctx: context.Background(), mcpCli: pool}
+}
@@ -0,0 +1,99 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+)
+
+func TestRunTools(t *testing.T) {
+	Convey("Test runTools", t, func() {
+		var active, maxActive atomic.Int64
+		s := mcpserver.NewMCPServer("test", "test", mcpserver.WithToolCapabilities(true))
+		// sleep 等待 ms 毫秒后返回 text，同时记录最大并发数
+		s.AddTool(mcp.NewTool("sleep"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				m := maxActive.Load()
+				if n <= m || maxActive.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(req.GetFloat("ms", 0)) * time.Millisecond)
+			return mcp.NewToolResultText(req.GetString("text", "")), nil
+		})
+		s.AddTool(mcp.NewTool("fail"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("boom")
+		})
+		h := newTestHost(s)
+
+		cfg := new(config.Config)
+		config.AiProvider = &cfg.AiProvider
+		Reset(func() { config.AiProvider = nil })
+
+		calls := []ai_provider.ToolCall{
+			{ID: "1", Function: ai_provider.ToolFunction{Name: "sleep"}},
+			{ID: "2", Function: ai_provider.ToolFunction{Name: "sleep"}},
+			{ID: "3", Function: ai_provider.ToolFunction{Name: "fail"}},
+			{ID: "4", Function: ai_provider.ToolFunction{Name: "sleep"}},
+		}
+		args := []any{
+			map[string]any{"ms": 300, "text": "a"},
+			map[string]any{"ms": 10, "text": "b"},
+			map[string]any{},
+			map[string]any{"ms": 50, "text": "d"},
+		}
+		var (
+			mu      sync.Mutex
+			results []string
+		)
+		emit := func(event string, v any) error {
+			if event == constant.SSEEventToolResult {
+				mu.Lock()
+				results = append(results, v.(map[string]any)["id"].(string))
+				mu.Unlock()
+			}
+			return nil
+		}
+
+		Convey("results in call order, failures isolated", func() {
+			cfg.AiProvider.ToolParallelism = 2
+			outcomes := h.runTools(context.Background(), "", ChatOptions{}, 1, calls, args, emit)
+			So(outcomes, ShouldHaveLength, 4)
+			So(outcomes[0].Result.Text, ShouldEqual, "a\n")
+			So(outcomes[1].Result.Text, ShouldEqual, "b\n")
+			So(outcomes[2].Status, ShouldEqual, constant.ToolOutcomeToolError)
+			So(outcomes[3].Result.Text, ShouldEqual, "d\n")
+			for _, i := range []int{0, 1, 3} {
+				So(outcomes[i].Status, ShouldEqual, constant.ToolOutcomeSuccess)
+			}
+
+			// 各调用完成即推送 tool_result，慢的第一个调用最后完成
+			So(results, ShouldHaveLength, 4)
+			So(results[len(results)-1], ShouldEqual, "1")
+			So(maxActive.Load(), ShouldEqual, 2)
+		})
+
+		Convey("parallelism limit", func() {
+			cfg.AiProvider.ToolParallelism = 1
+			start := time.Now()
+			outcomes := h.runTools(context.Background(), "", ChatOptions{}, 1, calls, args, emit)
+			So(outcomes[3].Result.Text, ShouldEqual, "d\n")
+			So(maxActive.Load(), ShouldEqual, 1)
+			So(results, ShouldResemble, []string{"1", "2", "3", "4"})
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 360*time.Millisecond)
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package host
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"slices"
+)
+
+// MCPServers 各 MCP Server 的健康状态
+func (h *Host) MCPServers(ctx context.Context) []mcp_client.ServerStatus {
+	if h.mcpCli == nil {
+		return nil
+	}
+	return h.mcpCli.Health(ctx)
+}
+
+// MCPResources 各 MCP Server 提供的资源与资源模板
+func (h *Host) MCPResources(ctx context.Context) ([]mcp_client.Resource, []mcp_client.ResourceTemplate) {
+	if h.mcpCli == nil {
+		return nil, nil
+	}
+	return h.mcpCli.Resources(ctx)
+}
+
+// ReadMCPResource 读取 MCP 资源，只配置了一个 server 时 server 可为空
+func (h *Host) ReadMCPResource(ctx context.Context, server, uri string) ([]mcp_client.Content, error) {
+	if uri == "" {
+		return nil, errno.Errorf(errno.ParamMissingCode, "缺少资源 uri")
+	}
+	if h.mcpCli == nil {
+		return nil, errno.Errorf(errno.BizErrorCode, "未配置 MCP Server")
+	}
+	contents, err := h.mcpCli.ReadResource(ctx, server, uri)
+	switch {
+	case err == nil:
+		return contents, nil
+	case errors.Is(err, mcp_client.ErrUnknownServer):
+		return nil, errno.Errorf(errno.ParamValueCode, "未知的 MCP Server: %s", server)
+	case mcp_client.IsTransportError(err) || ctx.Err() != nil:
+		return nil, err
+	default:
+		// server 返回的错误（资源不存在、路径越界等）
+		return nil, errno.Errorf(errno.ParamValueCode, "读取资源 %s 失败: %v", uri, err)
+	}
+}
+
+// userMessage 读取本次对话附带的 MCP 资源，与附件一起构造用户消息
+// 文本资源内联到正文，图片资源作为图片发给模型
+func (h *Host) userMessage(ctx context.Context, opts ChatOptions, content string) (ai_provider.Message, error) {
+	for _, ref := range opts.Resources {
+		contents, err := h.ReadMCPResource(ctx, ref.Server, ref.URI)
+		if err != nil {
+			return ai_provider.Message{}, err
+		}
+		for _, c := range contents {
+			a := Attachment{Name: cmp.Or(c.URI, ref.URI), ContentType: cmp.Or(c.MIMEType, "text/plain"), Content: c.Text}
+			if c.Data != "" {
+				a.ContentType, a.Content = cmp.Or(c.MIMEType, "application/octet-stream"), c.Data
+			}
+			opts.Attachments = append(slices.Clip(opts.Attachments), a)
+		}
+	}
+	return opts.userMessage(content)
+}
@@ -0,0 +1,125 @@
+package host
+
+import (
+	"encoding/base64"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"mime"
+	"strings"
+)
+
+// Attachment 随用户消息发送的附件
+type Attachment struct {
+	Name        string
+	ContentType string // MIME 类型，文本类型内联到消息中，image/* 作为图片发给模型
+	Content     string // 文本类型为原文，图片为 base64
+}
+
+// ResourceRef 随用户消息附带的 MCP 资源，读取后作为附件加入消息
+type ResourceRef struct {
+	Server string // 资源所属的 MCP Server，只配置了一个 server 时可为空
+	URI    string
+}
+
+// ChatOptions 单次对话的可选参数，零值表示全部使用配置
+type ChatOptions struct {
+	Model       string        // 覆盖 ai_provider.model
+	Temperature *float64      // 覆盖 ai_provider.options.temperature
+	Tools       []string      // 本次允许调用的工具，为空时不限制
+	Attachments []Attachment  // 附件
+	Resources   []ResourceRef // 附带的 MCP 资源
+	// RequireApproval 本次对话中策略为 auto 的工具也需要用户审批（见 tool_approval.Registry），超时视为拒绝
+	RequireApproval bool
+}
+
+// model 本次对话使用的模型
+func (o ChatOptions) model() string {
+	if o.Model != "" {
+		return o.Model
+	}
+	return config.AiProvider.Model
+}
+
+// ollamaOptions 在配置的基础上应用本次对话的覆盖项
+func (o ChatOptions) ollamaOptions() map[string]any {
+	opts := ai_provider.BuildOptions()
+	if o.Temperature != nil {
+		opts["temperature"] = *o.Temperature
+	}
+	return opts
+}
+
+// toolAllowed 工具是否在本次对话的白名单内
+func (o ChatOptions) toolAllowed(name string) bool {
+	if len(o.Tools) == 0 {
+		return true
+	}
+	for _, t := range o.Tools {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toolPolicy 工具的审批策略：按工具名配置 > tool_approval.default > auto，
+// 本次对话要求审批时 auto 也需要审批；无法识别的策略按 deny 处理
+func (o ChatOptions) toolPolicy(name string) string {
+	policy := constant.ToolPolicyAuto
+	if c := config.ToolApproval; c != nil {
+		if p, ok := c.Tools[name]; ok {
+			policy = p
+		} else if c.Default != "" {
+			policy = c.Default
+		}
+	}
+	switch policy {
+	case constant.ToolPolicyAuto:
+		if o.RequireApproval {
+			return constant.ToolPolicyRequireApproval
+		}
+		return policy
+	case constant.ToolPolicyRequireApproval:
+		return policy
+	default:
+		return constant.ToolPolicyDeny
+	}
+}
+
+// userMessage 构造本轮的用户消息：文本附件追加到正文，图片附件放入 Images；
+// 图片按原样落历史，模型不支持图片时在发送前换成文本占位（见 visionMessages）
+func (o ChatOptions) userMessage(content string) (ai_provider.Message, error) {
+	msg := ai_provider.Message{Role: "user", Content: content}
+	for _, a := range o.Attachments {
+		mediaType, _, err := mime.ParseMediaType(a.ContentType)
+		if err != nil {
+			return msg, errno.Errorf(errno.ParamFormatCode, "附件 %s 的 content_type 无效: %s", a.Name, a.ContentType)
+		}
+		switch {
+		case strings.HasPrefix(mediaType, "image/"):
+			if _, err := base64.StdEncoding.DecodeString(a.Content); err != nil {
+				return msg, errno.Errorf(errno.ParamFormatCode, "附件 %s 不是合法的 base64", a.Name)
+			}
+			msg.Images = append(msg.Images, a.Content)
+		case isTextType(mediaType):
+			msg.Content += "\n\n[附件 " + a.Name + "]\n" + a.Content
+		default:
+			return msg, errno.Errorf(errno.ParamTypeCode, "不支持的附件类型: %s", a.ContentType)
+		}
+	}
+	return msg, nil
+}
+
+// isTextType 可以直接内联到消息中的附件类型
+func isTextType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml":
+		return true
+	}
+	return false
+}
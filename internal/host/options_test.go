@@ -0,0 +1,44 @@
+package host
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+)
+
+func TestToolPolicy(t *testing.T) {
+	Convey("Test toolPolicy", t, func() {
+		Convey("without config", func() {
+			So(ChatOptions{}.toolPolicy("echo"), ShouldEqual, constant.ToolPolicyAuto)
+			So(ChatOptions{RequireApproval: true}.toolPolicy("echo"), ShouldEqual, constant.ToolPolicyRequireApproval)
+		})
+
+		Convey("with config", func() {
+			cfg := new(config.Config)
+			cfg.ToolApproval.Default = constant.ToolPolicyRequireApproval
+			cfg.ToolApproval.Tools = map[string]string{
+				"echo":   constant.ToolPolicyAuto,
+				"rm":     constant.ToolPolicyDeny,
+				"typo":   "allow",
+				"deploy": constant.ToolPolicyRequireApproval,
+			}
+			config.ToolApproval = &cfg.ToolApproval
+			Reset(func() { config.ToolApproval = nil })
+
+			So(ChatOptions{}.toolPolicy("echo"), ShouldEqual, constant.ToolPolicyAuto)
+			So(ChatOptions{}.toolPolicy("rm"), ShouldEqual, constant.ToolPolicyDeny)
+			So(ChatOptions{}.toolPolicy("deploy"), ShouldEqual, constant.ToolPolicyRequireApproval)
+			So(ChatOptions{}.toolPolicy("other"), ShouldEqual, constant.ToolPolicyRequireApproval)
+			// 无法识别的策略按 deny 处理
+			So(ChatOptions{}.toolPolicy("typo"), ShouldEqual, constant.ToolPolicyDeny)
+
+			// 本次对话要求审批时 auto 也需要审批，deny 不受影响
+			opts := ChatOptions{RequireApproval: true}
+			So(opts.toolPolicy("echo"), ShouldEqual, constant.ToolPolicyRequireApproval)
+			So(opts.toolPolicy("rm"), ShouldEqual, constant.ToolPolicyDeny)
+		})
+	})
+}
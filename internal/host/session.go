@@ -0,0 +1,127 @@
+package host
+
+import (
+	"context"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/conversation_store"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"github.com/google/uuid"
+)
+
+// OpenSession 打开一个会话并返回实际使用的会话 id
+// - sessionID 为空：创建新会话
+// - sessionID 不存在：以该 id 创建会话（允许客户端自行生成 id）
+// - sessionID 已存在且绑定了其他用户：返回 errno.SessionForbidden
+func (h *Host) OpenSession(ctx context.Context, sessionID, userID string) (string, error) {
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+	info, err := h.store.Info(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if info == nil {
+		if err := h.store.Create(ctx, conversation_store.Conversation{ID: sessionID, UserID: userID}); err != nil {
+			return "", err
+		}
+		// 并发创建同一 id 时以先落库的为准，重新读取一次归属
+		if info, err = h.store.Info(ctx, sessionID); err != nil {
+			return "", err
+		}
+	}
+	if info != nil && info.UserID != "" && info.UserID != userID {
+		return "", errno.SessionForbidden
+	}
+	return sessionID, nil
+}
+
+// CreateSession 创建一个空会话
+func (h *Host) CreateSession(ctx context.Context, userID, title string) (*conversation_store.Conversation, error) {
+	sessionID := uuid.NewString()
+	if err := h.store.Create(ctx, conversation_store.Conversation{ID: sessionID, UserID: userID, Title: title}); err != nil {
+		return nil, err
+	}
+	return h.store.Info(ctx, sessionID)
+}
+
+// ListSessions 列出属于 userID 的会话，userID 为空时只列出未绑定用户的会话
+func (h *Host) ListSessions(ctx context.Context, userID string) ([]conversation_store.Conversation, error) {
+	all, err := h.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]conversation_store.Conversation, 0, len(all))
+	for _, c := range all {
+		if c.UserID == userID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// GetSession 获取会话概要与完整消息记录
+func (h *Host) GetSession(ctx context.Context, sessionID, userID string) (*conversation_store.Conversation, []ai_provider.Message, error) {
+	info, err := h.session(ctx, sessionID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	msgs, err := h.store.Get(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return info, msgs, nil
+}
+
+// RenameSession 修改会话标题
+func (h *Host) RenameSession(ctx context.Context, sessionID, userID, title string) (*conversation_store.Conversation, error) {
+	if _, err := h.session(ctx, sessionID, userID); err != nil {
+		return nil, err
+	}
+	if err := h.store.Rename(ctx, sessionID, title); err != nil {
+		return nil, err
+	}
+	return h.store.Info(ctx, sessionID)
+}
+
+// DeleteSession 删除会话及其全部记录
+func (h *Host) DeleteSession(ctx context.Context, sessionID, userID string) error {
+	if _, err := h.session(ctx, sessionID, userID); err != nil {
+		return err
+	}
+	if err := h.store.Delete(ctx, sessionID); err != nil {
+		return err
+	}
+	if h.toolCache != nil {
+		h.toolCache.Delete(sessionID)
+	}
+	return nil
+}
+
+// ClearSession 清空会话消息记录，保留会话本身
+func (h *Host) ClearSession(ctx context.Context, sessionID, userID string) (*conversation_store.Conversation, error) {
+	if _, err := h.session(ctx, sessionID, userID); err != nil {
+		return nil, err
+	}
+	if err := h.store.Truncate(ctx, sessionID, 0); err != nil {
+		return nil, err
+	}
+	if h.toolCache != nil {
+		h.toolCache.Delete(sessionID)
+	}
+	return h.store.Info(ctx, sessionID)
+}
+
+// session 读取已存在的会话并校验归属
+func (h *Host) session(ctx context.Context, sessionID, userID string) (*conversation_store.Conversation, error) {
+	info, err := h.store.Info(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, errno.SessionNotExist
+	}
+	if info.UserID != "" && info.UserID != userID {
+		return nil, errno.SessionForbidden
+	}
+	return info, nil
+}
@@ -0,0 +1,45 @@
+package host
+
+import (
+	"context"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+)
+
+// watchEmit 包装推送函数：推送失败（通常是客户端已断开）时取消返回的 ctx，
+// 从而中断模型流式生成和正在执行的 MCP 工具调用
+func watchEmit(ctx context.Context, emit func(event string, v any) error) (context.Context, func(event string, v any) error, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, func(event string, v any) error {
+		err := emit(event, v)
+		if err != nil {
+			cancel()
+		}
+		return err
+	}, cancel
+}
+
+// cancelTurn 对话被取消时保存本轮已产生的内容，并追加一条状态为 cancelled 的 assistant 消息（含已生成的部分回复）
+func (h *Host) cancelTurn(ctx context.Context, sessionID string, turn []ai_provider.Message, partial string) error {
+	logger.Infof("[chat] session %s cancelled: %v", sessionID, ctx.Err())
+	turn = append(turn, ai_provider.Message{Role: "assistant", Content: partial, Status: constant.MessageStatusCancelled})
+	// 请求 ctx 已取消，落库使用不受取消影响的 ctx
+	if err := h.store.Append(context.WithoutCancel(ctx), sessionID, turn...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// toolCallsPayload start_tool_call 事件中的工具调用列表，两条对话链路统一为 {id, name, arguments}
+func toolCallsPayload(calls []ai_provider.ToolCall) []map[string]any {
+	out := make([]map[string]any, 0, len(calls))
+	for _, tc := range calls {
+		out = append(out, map[string]any{
+			"id":        tc.ID,
+			"name":      tc.Function.Name,
+			"arguments": tc.Function.ArgumentsString(),
+		})
+	}
+	return out
+}
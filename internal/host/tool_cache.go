@@ -0,0 +1,41 @@
+package host
+
+import (
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"time"
+)
+
+// toolCachePolicy 工具结果的缓存策略，返回缓存时长（0 表示不缓存）以及是否为写类型的工具（执行后清空会话缓存）。
+//   - tool_cache.tools 按工具名配置的缓存时长优先，视为没有副作用
+//   - 声明了 readOnlyHint 的工具使用 tool_cache.ttl
+//   - 只声明了 idempotentHint 的工具有副作用，同样使用 tool_cache.ttl，但仍是写类型：实际执行时先清空会话缓存再缓存自己的结果，
+//     相同参数的重复调用直接返回结果（重复执行也不会有额外影响），期间执行了其他写类型的工具则重新执行
+//   - 其余工具视为写类型，不缓存
+//
+// 未启用缓存或没有会话（如 OpenAI 兼容接口）时不缓存
+func (h *Host) toolCachePolicy(sessionID, name string) (time.Duration, bool) {
+	if h.toolCache == nil || sessionID == "" || config.ToolCache == nil {
+		return 0, false
+	}
+	tool, ok := h.mcpCli.Tool(name)
+	if !ok {
+		return 0, false
+	}
+	readOnly := mcp_client.IsReadOnly(tool)
+	if ttl, ok := config.ToolCache.Tools[name]; ok {
+		if ttl > 0 {
+			return ttl, false
+		}
+		return 0, !readOnly
+	}
+	if !mcp_client.IsIdempotent(tool) {
+		return 0, true
+	}
+	ttl := constant.ToolCacheDefaultTTL
+	if config.ToolCache.TTL > 0 {
+		ttl = config.ToolCache.TTL
+	}
+	return ttl, !readOnly
+}
@@ -0,0 +1,119 @@
+package host
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/internal/mcp_server/tool"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_cache"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_set"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+)
+
+func TestToolCachePolicy(t *testing.T) {
+	Convey("Test toolCachePolicy", t, func() {
+		s := mcpserver.NewMCPServer("test", "test", mcpserver.WithToolCapabilities(true))
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		}
+		s.AddTool(mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true)), handler)
+		s.AddTool(mcp.NewTool("idempotent", mcp.WithReadOnlyHintAnnotation(false), mcp.WithIdempotentHintAnnotation(true)), handler)
+		s.AddTool(mcp.NewTool("write", mcp.WithReadOnlyHintAnnotation(false)), handler)
+		// 每次执行返回递增的计数，用于区分是否命中缓存
+		var calls atomic.Int64
+		count := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(strconv.FormatInt(calls.Add(1), 10)), nil
+		}
+		s.AddTool(mcp.NewTool("count_read", mcp.WithReadOnlyHintAnnotation(true)), count)
+		s.AddTool(mcp.NewTool("count_set", mcp.WithReadOnlyHintAnnotation(false), mcp.WithIdempotentHintAnnotation(true)), count)
+		// 内置工具
+		builtin := &tool_set.ToolSet{HandlerFunc: make(map[string]mcpserver.ToolHandlerFunc)}
+		tool.WithTimeTool()(builtin)
+		tool.WithLongRunningOperationTool()(builtin)
+		for _, t := range builtin.Tools {
+			s.AddTool(*t, builtin.HandlerFunc[t.Name])
+		}
+		h := newTestHost(s)
+		h.toolCache = tool_cache.NewCache(constant.ToolCacheMaxSessionEntries)
+
+		cfg := new(config.Config)
+		cfg.ToolCache.Enable = true
+		config.ToolCache = &cfg.ToolCache
+		Reset(func() { config.ToolCache = nil })
+
+		policy := func(name string) []any {
+			ttl, write := h.toolCachePolicy("s", name)
+			return []any{ttl, write}
+		}
+
+		Convey("read only tools are cached", func() {
+			So(policy("read"), ShouldResemble, []any{constant.ToolCacheDefaultTTL, false})
+			cfg.ToolCache.TTL = time.Second
+			So(policy("read"), ShouldResemble, []any{time.Second, false})
+		})
+
+		Convey("built-in read only tools are not writes", func() {
+			So(policy("time_now"), ShouldResemble, []any{constant.ToolCacheDefaultTTL, false})
+			So(policy("long_running_tool"), ShouldResemble, []any{constant.ToolCacheDefaultTTL, false})
+			cfg.ToolCache.Tools = map[string]time.Duration{"time_now": 0}
+			So(policy("time_now"), ShouldResemble, []any{time.Duration(0), false})
+		})
+
+		Convey("idempotent tools are cached writes", func() {
+			So(policy("idempotent"), ShouldResemble, []any{constant.ToolCacheDefaultTTL, true})
+		})
+
+		Convey("other tools are uncached writes", func() {
+			So(policy("write"), ShouldResemble, []any{time.Duration(0), true})
+			So(policy("missing"), ShouldResemble, []any{time.Duration(0), false})
+		})
+
+		Convey("per tool ttl", func() {
+			cfg.ToolCache.Tools = map[string]time.Duration{"write": time.Second, "read": 0, "idempotent": 0}
+			So(policy("write"), ShouldResemble, []any{time.Second, false})
+			So(policy("read"), ShouldResemble, []any{time.Duration(0), false})
+			So(policy("idempotent"), ShouldResemble, []any{time.Duration(0), true})
+		})
+
+		Convey("calls", func() {
+			call := func(name string) (string, bool) {
+				res, cached, err := h.callTool(context.Background(), "s", ChatOptions{}, 1,
+					ai_provider.ToolCall{Function: ai_provider.ToolFunction{Name: name}}, map[string]any{}, func(string, any) error { return nil })
+				So(err, ShouldBeNil)
+				return res.Text, cached
+			}
+
+			text, cached := call("count_read")
+			So(cached, ShouldBeFalse)
+			// 幂等的写类型工具执行时清空会话缓存，自己的结果仍被缓存
+			set, cached := call("count_set")
+			So(cached, ShouldBeFalse)
+			again, cached := call("count_set")
+			So(cached, ShouldBeTrue)
+			So(again, ShouldEqual, set)
+			read, cached := call("count_read")
+			So(cached, ShouldBeFalse)
+			So(read, ShouldNotEqual, text)
+
+			// 其他写类型的工具清空幂等工具的缓存
+			_, _ = call("write")
+			_, cached = call("count_set")
+			So(cached, ShouldBeFalse)
+		})
+
+		Convey("no session", func() {
+			ttl, write := h.toolCachePolicy("", "write")
+			So(ttl, ShouldEqual, 0)
+			So(write, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,117 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/FantasyRL/go-mcp-demo/pkg/metrics"
+	"time"
+)
+
+// toolOutcome 一次工具调用的结果
+type toolOutcome struct {
+	Status string                 // 结果分类，constant.ToolOutcome*
+	Result *mcp_client.ToolResult // 交给模型的结果，失败时为统一格式的错误说明
+	Error  string                 // 失败原因，成功时为空
+	Cached bool                   // 结果来自缓存，未调用 MCP Server
+}
+
+// runTool 执行一次工具调用，对结果分类并记录监控指标
+func (h *Host) runTool(
+	ctx context.Context,
+	sessionID string,
+	opts ChatOptions,
+	round int,
+	call ai_provider.ToolCall,
+	args any,
+	emit func(event string, v any) error,
+) toolOutcome {
+	start := time.Now()
+	res, cached, err := h.callTool(ctx, sessionID, opts, round, call, args, emit)
+	o := newToolOutcome(call.Function.Name, res, err)
+	o.Cached = cached
+	metrics.ObserveToolCall(h.metricToolName(call.Function.Name), o.Status, cached, time.Since(start))
+	if o.Status != constant.ToolOutcomeSuccess {
+		logger.Warnf("[tool round %d] %s %s: %s", round, call.Function.Name, o.Status, o.Error)
+	}
+	return o
+}
+
+// metricToolName 监控指标中的工具名：工具名由模型给出，不在工具列表中的统一记为 unknown，避免标签基数无限增长
+func (h *Host) metricToolName(name string) string {
+	if _, ok := h.mcpCli.Tool(name); ok {
+		return name
+	}
+	return metrics.UnknownTool
+}
+
+// newToolOutcome 对工具调用结果分类。失败时交给模型的内容统一为
+// {"status": 分类, "tool": 工具名, "error": 原因}，超时额外带 timeout_seconds
+func newToolOutcome(name string, res *mcp_client.ToolResult, err error) toolOutcome {
+	if err == nil && !res.IsError {
+		return toolOutcome{Status: constant.ToolOutcomeSuccess, Result: res}
+	}
+
+	o := toolOutcome{Status: constant.ToolOutcomeToolError}
+	detail := map[string]any{"tool": name}
+	var (
+		e       errno.ErrNo
+		timeout *mcp_client.TimeoutError
+	)
+	switch {
+	case err == nil:
+		// 工具自身报告失败，其返回的文本即为原因
+		o.Error = res.Text
+	case errors.As(err, &timeout):
+		o.Status, o.Error = constant.ToolOutcomeTimeout, "工具执行超时，已取消本次调用"
+		detail["timeout_seconds"] = timeout.Timeout.Seconds()
+	case errors.Is(err, context.Canceled):
+		o.Status, o.Error = constant.ToolOutcomeCancelled, "调用已取消"
+	case errors.As(err, &e) && e.ErrorCode == errno.BizToolDeniedCode:
+		o.Status, o.Error = constant.ToolOutcomeDenied, e.ErrorMsg
+	case mcp_client.IsTransportError(err):
+		o.Status, o.Error = constant.ToolOutcomeTransportError, err.Error()
+	default:
+		o.Error = err.Error()
+	}
+	detail["status"], detail["error"] = o.Status, o.Error
+	b, _ := json.Marshal(detail)
+	o.Result = mcp_client.TextResult(string(b))
+	return o
+}
+
+// toolResultPayload tool_result 事件的 data：result 为交给模型的文本，content 为按类型区分的全部内容（图片等）
+func toolResultPayload(round int, call ai_provider.ToolCall, o toolOutcome) map[string]any {
+	data := map[string]any{
+		"round":   round,
+		"id":      call.ID,
+		"name":    call.Function.Name,
+		"status":  o.Status,
+		"result":  o.Result.Text,
+		"content": o.Result.Contents,
+	}
+	if o.Error != "" {
+		data["error"] = o.Error
+	}
+	if o.Cached {
+		data["cached"] = true
+	}
+	return data
+}
+
+// toolMessage 工具结果落历史的消息，图片放入 Images，只有支持图片的模型才会收到（见 visionMessages）
+func toolMessage(call ai_provider.ToolCall, o toolOutcome) ai_provider.Message {
+	return ai_provider.Message{
+		Role:       "tool",
+		ToolName:   call.Function.Name,
+		ToolCallID: call.ID,
+		Content:    o.Result.Text,
+		Images:     o.Result.Images(),
+		ToolStatus: o.Status,
+	}
+}
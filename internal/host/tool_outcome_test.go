@@ -0,0 +1,78 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"github.com/FantasyRL/go-mcp-demo/pkg/metrics"
+)
+
+func TestToolOutcome(t *testing.T) {
+	Convey("Test newToolOutcome", t, func() {
+		detail := func(o toolOutcome) map[string]any {
+			var m map[string]any
+			So(json.Unmarshal([]byte(o.Result.Text), &m), ShouldBeNil)
+			return m
+		}
+
+		Convey("success", func() {
+			res := mcp_client.TextResult("ok")
+			o := newToolOutcome("echo", res, nil)
+			So(o.Status, ShouldEqual, constant.ToolOutcomeSuccess)
+			So(o.Result, ShouldEqual, res)
+			So(o.Error, ShouldBeEmpty)
+		})
+
+		Convey("tool error", func() {
+			res := mcp_client.TextResult("bad input")
+			res.IsError = true
+			o := newToolOutcome("echo", res, nil)
+			So(o.Status, ShouldEqual, constant.ToolOutcomeToolError)
+			So(o.Error, ShouldEqual, "bad input")
+			So(detail(o), ShouldResemble, map[string]any{"status": "tool_error", "tool": "echo", "error": "bad input"})
+
+			o = newToolOutcome("echo", nil, errors.New("invalid arguments"))
+			So(o.Status, ShouldEqual, constant.ToolOutcomeToolError)
+			So(o.Error, ShouldEqual, "invalid arguments")
+		})
+
+		Convey("timeout", func() {
+			err := fmt.Errorf("call: %w", &mcp_client.TimeoutError{Tool: "echo", Timeout: 2 * time.Second})
+			o := newToolOutcome("echo", nil, err)
+			So(o.Status, ShouldEqual, constant.ToolOutcomeTimeout)
+			So(detail(o)["timeout_seconds"], ShouldEqual, 2)
+		})
+
+		Convey("cancelled, denied and transport error", func() {
+			So(newToolOutcome("echo", nil, context.Canceled).Status, ShouldEqual, constant.ToolOutcomeCancelled)
+
+			o := newToolOutcome("echo", nil, errno.ToolDenied.WithMessage("denied by policy"))
+			So(o.Status, ShouldEqual, constant.ToolOutcomeDenied)
+			So(o.Error, ShouldEqual, "denied by policy")
+
+			o = newToolOutcome("echo", nil, fmt.Errorf("call: %w", mcp_client.ErrServerUnavailable))
+			So(o.Status, ShouldEqual, constant.ToolOutcomeTransportError)
+		})
+	})
+
+	Convey("Test metricToolName", t, func() {
+		s := mcpserver.NewMCPServer("test", "test", mcpserver.WithToolCapabilities(true))
+		s.AddTool(mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+		h := newTestHost(s)
+		So(h.metricToolName("echo"), ShouldEqual, "echo")
+		So(h.metricToolName("made_up_tool"), ShouldEqual, metrics.UnknownTool)
+	})
+}
@@ -0,0 +1,57 @@
+package host
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// supportsVision 模型是否支持图片输入（ai_provider.vision_models）
+func supportsVision(model string) bool {
+	return config.AiProvider != nil && slices.Contains(config.AiProvider.VisionModels, model)
+}
+
+// imagePlaceholder 发给不支持图片的模型时代替图片的文本，如 [image: image/png, 1024 bytes]
+func imagePlaceholder(b64 string) string {
+	head, _ := base64.StdEncoding.DecodeString(b64[:min(len(b64), 64)])
+	size := base64.StdEncoding.DecodedLen(len(b64)) - strings.Count(b64[max(len(b64)-2, 0):], "=")
+	return fmt.Sprintf("[image: %s, %d bytes]", http.DetectContentType(head), size)
+}
+
+// visionMessages 构造请求时按模型处理消息中的图片：不支持图片的模型只收到文本占位，
+// 存储中的记录保留图片，换用支持图片的模型后仍可发送；不修改传入的消息
+func visionMessages(model string, msgs []ai_provider.Message) []ai_provider.Message {
+	if supportsVision(model) {
+		return msgs
+	}
+	var out []ai_provider.Message
+	for i, m := range msgs {
+		if len(m.Images) == 0 {
+			if out != nil {
+				out = append(out, m)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]ai_provider.Message, 0, len(msgs)), msgs[:i]...)
+		}
+		placeholders := make([]string, 0, len(m.Images))
+		for _, img := range m.Images {
+			placeholders = append(placeholders, imagePlaceholder(img))
+		}
+		if m.Content != "" && !strings.HasSuffix(m.Content, "\n") {
+			m.Content += "\n"
+		}
+		m.Content += strings.Join(placeholders, "\n")
+		m.Images = nil
+		out = append(out, m)
+	}
+	if out == nil {
+		return msgs
+	}
+	return out
+}
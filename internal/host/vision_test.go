@@ -0,0 +1,63 @@
+package host
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+)
+
+// png PNG 文件头的 base64，解码后 8 字节
+const png = "iVBORw0KGgo="
+
+func TestVision(t *testing.T) {
+	Convey("Test vision gating", t, func() {
+		config.AiProvider = &config.AiProviderConfig{Model: "deepseek-chat", VisionModels: []string{"llava"}}
+		Reset(func() { config.AiProvider = nil })
+
+		Convey("placeholder", func() {
+			So(imagePlaceholder(png), ShouldEqual, "[image: image/png, 8 bytes]")
+		})
+
+		Convey("messages", func() {
+			msgs := []ai_provider.Message{
+				{Role: "user", Content: "look"},
+				{Role: "tool", Content: "screenshot", Images: []string{png, png}},
+			}
+			So(visionMessages("llava", msgs), ShouldResemble, msgs)
+
+			out := visionMessages("deepseek-chat", msgs)
+			So(out[0], ShouldResemble, msgs[0])
+			So(out[1].Content, ShouldEqual, "screenshot\n[image: image/png, 8 bytes]\n[image: image/png, 8 bytes]")
+			So(out[1].Images, ShouldBeNil)
+			// 不修改传入的消息
+			So(msgs[1].Images, ShouldHaveLength, 2)
+			So(msgs[1].Content, ShouldEqual, "screenshot")
+		})
+
+		Convey("attachments", func() {
+			ctx := context.Background()
+			opts := ChatOptions{Attachments: []Attachment{{Name: "a.png", ContentType: "image/png", Content: png}}}
+			// 落历史的用户消息保留图片，之后换用支持图片的模型仍可发送
+			msg, err := opts.userMessage("hi")
+			So(err, ShouldBeNil)
+			So(msg.Images, ShouldResemble, []string{png})
+			So(msg.Content, ShouldEqual, "hi")
+
+			// 只有发给不支持图片的模型的请求中换成文本占位
+			h := &Host{}
+			out, err := h.newContextWindow("deepseek-chat").fit(ctx, []ai_provider.Message{msg})
+			So(err, ShouldBeNil)
+			So(out[0].Images, ShouldBeNil)
+			So(out[0].Content, ShouldEqual, "hi\n[image: image/png, 8 bytes]")
+			So(msg.Images, ShouldResemble, []string{png})
+
+			out, err = h.newContextWindow("llava").fit(ctx, []ai_provider.Message{msg})
+			So(err, ShouldBeNil)
+			So(out[0], ShouldResemble, msg)
+		})
+	})
+}
@@ -0,0 +1,166 @@
+package resource
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// FileDirs 整理配置中允许访问的目录（相对项目目录，即 mcp_server 的工作目录），
+// 忽略超出项目目录或本身不允许访问（见 deniedPath）的目录
+func FileDirs(dirs []string) []string {
+	var out []string
+	for _, d := range dirs {
+		d = filepath.Clean(filepath.FromSlash(d))
+		if !filepath.IsLocal(d) || deniedPath(d) {
+			logger.Warnf("resource: file dir %s is not allowed, skipped", d)
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// FileHandler 读取项目文件的资源处理函数，只能访问 dirs（见 FileDirs）中的文件，目录返回其中的条目
+// - path 为空时列出允许访问的目录
+// - 以 . 开头的文件或目录（.git、.env 等）与 config 目录始终不允许访问，目录列表中也不出现
+// - 每个目录单独作为根打开，指向目录外的符号链接无法访问
+func FileHandler(dirs []string) func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		p := strings.Trim(argument(req, "path"), "/")
+		if p == "" {
+			var b strings.Builder
+			for _, d := range dirs {
+				b.WriteString(filepath.ToSlash(d) + "/\n")
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/plain", Text: b.String()}}, nil
+		}
+		p = filepath.Clean(filepath.FromSlash(p))
+		if !filepath.IsLocal(p) || deniedPath(p) {
+			return nil, fmt.Errorf("path %s is not allowed", p)
+		}
+		dir, rel, ok := allowedDir(dirs, p)
+		if !ok {
+			return nil, fmt.Errorf("path %s is not in the allowed directories", p)
+		}
+		return readFile(req.Params.URI, dir, rel, p)
+	}
+}
+
+// deniedPath 始终不允许访问的路径：任一部分以 . 开头，或位于项目的 config 目录下（含密钥等配置）
+func deniedPath(p string) bool {
+	if p == "." {
+		return false
+	}
+	parts := strings.Split(p, string(filepath.Separator))
+	if strings.EqualFold(parts[0], "config") {
+		return true
+	}
+	return slices.ContainsFunc(parts, func(s string) bool { return strings.HasPrefix(s, ".") })
+}
+
+// allowedDir 路径所在的允许访问的目录，以及路径相对该目录的部分
+func allowedDir(dirs []string, p string) (dir, rel string, ok bool) {
+	for _, d := range dirs {
+		if d == "." {
+			return d, p, true
+		}
+		if p == d {
+			return d, ".", true
+		}
+		if r, found := strings.CutPrefix(p, d+string(filepath.Separator)); found {
+			return d, r, true
+		}
+	}
+	return "", "", false
+}
+
+// readFile 以 dir 为根读取 rel，p 为相对项目目录的路径
+func readFile(uri, dir, rel, p string) ([]mcp.ResourceContents, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	f, err := root.Open(rel)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		entries, err := f.ReadDir(-1)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(entries, func(a, b os.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+		var b strings.Builder
+		for _, e := range entries {
+			if deniedPath(filepath.Join(p, e.Name())) {
+				continue
+			}
+			b.WriteString(e.Name())
+			if e.IsDir() {
+				b.WriteString("/")
+			}
+			b.WriteString("\n")
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "text/plain", Text: b.String()}}, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, constant.MCPResourceMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > constant.MCPResourceMaxBytes {
+		return nil, fmt.Errorf("file %s is larger than %d bytes", p, constant.MCPResourceMaxBytes)
+	}
+	mimeType, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(p)), ";")
+	if utf8.Valid(data) {
+		// 系统 MIME 表中部分源码扩展名不是文本类型（如 .mod 为 audio/x-mod），统一按纯文本返回
+		if !isTextType(mimeType) {
+			mimeType = "text/plain"
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(data)}}, nil
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)}}, nil
+}
+
+// isTextType 可以作为文本资源返回的 MIME 类型
+func isTextType(mimeType string) bool {
+	switch mimeType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml":
+		return true
+	}
+	return strings.HasPrefix(mimeType, "text/")
+}
+
+// argument 资源模板中变量的值，直接注册的资源没有变量，返回空字符串
+func argument(req mcp.ReadResourceRequest, name string) string {
+	switch v := req.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) != 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
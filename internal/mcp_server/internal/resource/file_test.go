@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFileHandler(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for name, content := range map[string]string{
+		"config/config.yaml": "api_key: secret",
+		"docs/a.md":          "# a",
+		"docs/.secret":       "secret",
+		".env":               "KEY=secret",
+		"idl/api.thrift":     "struct A {}",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(dir, "config", "config.yaml"), filepath.Join("docs", "link.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(dirs []string, path string) (string, error) {
+		req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "file:///" + path, Arguments: map[string]any{"path": path}}}
+		contents, err := FileHandler(FileDirs(dirs))(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return contents[0].(mcp.TextResourceContents).Text, nil
+	}
+
+	Convey("Test FileHandler", t, func() {
+		Convey("allowed dirs", func() {
+			So(FileDirs([]string{"docs/", "config", ".git", "..", "/etc"}), ShouldResemble, []string{"docs"})
+
+			text, err := read([]string{"docs"}, "")
+			So(err, ShouldBeNil)
+			So(text, ShouldEqual, "docs/\n")
+			text, err = read([]string{"docs"}, "docs/a.md")
+			So(err, ShouldBeNil)
+			So(text, ShouldEqual, "# a")
+
+			_, err = read([]string{"docs"}, "idl/api.thrift")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("config and dotfiles are refused", func() {
+			for _, dirs := range [][]string{{"docs"}, {"."}, {"docs", "config"}} {
+				_, err := read(dirs, "config/config.yaml")
+				So(err, ShouldNotBeNil)
+				_, err = read(dirs, "docs/.secret")
+				So(err, ShouldNotBeNil)
+				_, err = read(dirs, "docs/../config/config.yaml")
+				So(err, ShouldNotBeNil)
+			}
+			_, err := read([]string{"."}, ".env")
+			So(err, ShouldNotBeNil)
+
+			text, err := read([]string{"."}, ".")
+			So(err, ShouldBeNil)
+			So(text, ShouldEqual, "docs/\nidl/\n")
+			text, err = read([]string{"docs"}, "docs")
+			So(err, ShouldBeNil)
+			So(text, ShouldEqual, "a.md\nlink.yaml\n")
+		})
+
+		Convey("symlinks out of the allowed dir are refused", func() {
+			_, err := read([]string{"docs"}, "docs/link.yaml")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LogURIPrefix log 资源的 URI 前缀，其后为服务名
+const LogURIPrefix = "log://"
+
+var serviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// HandleLog 返回服务当天日志的最后若干行
+// 日志按 logger 的规则写在可执行文件所在目录下，只能读取与 mcp_server 位于同一目录的服务（如 host）的日志
+func HandleLog(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	service := argument(req, "service")
+	if service == "" {
+		service = strings.TrimPrefix(req.Params.URI, LogURIPrefix)
+	}
+	if !serviceNamePattern.MatchString(service) {
+		return nil, fmt.Errorf("invalid service name: %s", service)
+	}
+	path, err := logger.FilePath(service, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	text, err := tailLines(path, constant.MCPLogResourceTailLines)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/plain", Text: text}}, nil
+}
+
+// tailLines 读取文件最后 n 行，最多读取文件末尾 MCPResourceMaxBytes 字节
+func tailLines(path string, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := max(info.Size()-constant.MCPResourceMaxBytes, 0)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	// 从文件中间开始读时第一行不完整
+	if offset > 0 && len(lines) > 1 {
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
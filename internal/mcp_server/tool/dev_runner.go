@@ -22,6 +22,7 @@ func WithDevRunnerTools() tool_set.Option {
 			mcp.WithNumber("depth", mcp.Description("Max depth to traverse (default 4)")),
 			// ignore 如 node_modules, *.log
 			mcp.WithString("ignore", mcp.Description("Comma-separated glob patterns to ignore (optional)")),
+			mcp.WithReadOnlyHintAnnotation(true),
 		)
 		toolSet.Tools = append(toolSet.Tools, &toolTree)
 		toolSet.HandlerFunc[toolTree.Name] = dev_runner.HandleFsTree
@@ -33,6 +34,7 @@ func WithDevRunnerTools() tool_set.Option {
 			mcp.WithString("path", mcp.Required(), mcp.Description("File path to read")),
 			// 最大读取字节数
 			mcp.WithNumber("max_bytes", mcp.Description("Max bytes to read (default 65536)")),
+			mcp.WithReadOnlyHintAnnotation(true),
 		)
 		toolSet.Tools = append(toolSet.Tools, &toolCat)
 		toolSet.HandlerFunc[toolCat.Name] = dev_runner.HandleFsCat
@@ -0,0 +1,53 @@
+package tool
+
+import (
+	"github.com/FantasyRL/go-mcp-demo/internal/mcp_server/internal/resource"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_set"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithFileResources 项目文件资源，只能访问 dirs（mcp.resources.file_dirs）中的目录，dirs 为空时不注册
+// - file:/// ：允许访问的目录
+// - file:///{+path} ：允许访问的目录下的文件或目录，path 为相对项目目录（mcp_server 的工作目录）的路径
+func WithFileResources(dirs []string) tool_set.Option {
+	return func(toolSet *tool_set.ToolSet) {
+		allowed := resource.FileDirs(dirs)
+		if len(allowed) == 0 {
+			return
+		}
+		handle := resource.FileHandler(allowed)
+		root := mcp.NewResource("file:///", "project",
+			mcp.WithResourceDescription("Project directories that can be read"),
+			mcp.WithMIMEType("text/plain"),
+		)
+		toolSet.Resources = append(toolSet.Resources, &root)
+		toolSet.ResourceHandlerFunc[root.URI] = handle
+
+		file := mcp.NewResourceTemplate("file:///{+path}", "project_file",
+			mcp.WithTemplateDescription("A file or directory in the readable project directories, path is relative to the project root"),
+		)
+		toolSet.ResourceTemplates = append(toolSet.ResourceTemplates, &file)
+		toolSet.ResourceTemplateHandlerFunc[file.URITemplate.Raw()] = handle
+	}
+}
+
+// WithLogResources 最近日志资源
+// - log://host ：host 当天日志的最后若干行
+// - log://{service} ：指定服务当天日志的最后若干行
+func WithLogResources() tool_set.Option {
+	return func(toolSet *tool_set.ToolSet) {
+		hostLog := mcp.NewResource(resource.LogURIPrefix+"host", "host_log",
+			mcp.WithResourceDescription("Recent log lines of the host service"),
+			mcp.WithMIMEType("text/plain"),
+		)
+		toolSet.Resources = append(toolSet.Resources, &hostLog)
+		toolSet.ResourceHandlerFunc[hostLog.URI] = resource.HandleLog
+
+		serviceLog := mcp.NewResourceTemplate(resource.LogURIPrefix+"{service}", "service_log",
+			mcp.WithTemplateDescription("Recent log lines of a service (host, mcp_server, cli)"),
+			mcp.WithTemplateMIMEType("text/plain"),
+		)
+		toolSet.ResourceTemplates = append(toolSet.ResourceTemplates, &serviceLog)
+		toolSet.ResourceTemplateHandlerFunc[serviceLog.URITemplate.Raw()] = resource.HandleLog
+	}
+}
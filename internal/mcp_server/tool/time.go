@@ -15,6 +15,7 @@ func WithTimeTool() tool_set.Option {
 		newTool := mcp.NewTool(
 			"time_now",
 			mcp.WithDescription("返回当前时间（RFC3339）"),
+			mcp.WithReadOnlyHintAnnotation(true),
 		)
 		toolFunc := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			now := time.Now().Format(time.RFC3339)
@@ -37,6 +38,7 @@ func WithLongRunningOperationTool() tool_set.Option {
 				mcp.Description("Number of steps to complete the operation"),
 				mcp.Required(),
 			),
+			mcp.WithReadOnlyHintAnnotation(true),
 		)
 		// handleLongRunningOperationTool 示例长时间运行的工具，支持进度汇报
 		// https://github.com/mark3labs/mcp-go/blob/main/examples/everything/main.go 413
@@ -46,8 +48,11 @@ func WithLongRunningOperationTool() tool_set.Option {
 		) (*mcp.CallToolResult, error) {
 			// 从请求中提取工具参数
 			arguments := request.GetArguments()
-			// 从请求元数据中提取进度标识符
-			progressToken := request.Params.Meta.ProgressToken
+			// 从请求元数据中提取进度标识符，调用方不关心进度时可能没有元数据
+			var progressToken mcp.ProgressToken
+			if request.Params.Meta != nil {
+				progressToken = request.Params.Meta.ProgressToken
+			}
 
 			// 获取任务总持续时间和步骤数
 			duration, _ := arguments["duration"].(float64) // 任务总持续时间（秒）
@@ -61,8 +66,12 @@ func WithLongRunningOperationTool() tool_set.Option {
 
 			// 执行任务：模拟长时间操作，并在每一步发送进度通知
 			for i := 1; i < int(steps)+1; i++ {
-				// 每步执行完成后，等待相应的时间（模拟耗时操作）
-				time.Sleep(time.Duration(stepDuration * float64(time.Second)))
+				// 每步执行完成后，等待相应的时间（模拟耗时操作），请求被取消时提前结束
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Duration(stepDuration * float64(time.Second))):
+				}
 
 				// 如果有进度令牌（progressToken），则发送进度通知
 				if progressToken != nil {
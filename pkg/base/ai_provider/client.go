@@ -88,7 +88,7 @@ func (c *Client) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, erro
 	endpoint := fmt.Sprintf("%s/api/chat", c.baseURL)
 	req.Stream = false
 
-	b, _ := json.Marshal(req)
+	b, _ := json.Marshal(req.wire())
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
 	if err != nil {
 		logger.Errorf("ollama.Chat NewRequestWithContext error: %v", err)
@@ -120,7 +120,7 @@ func (c *Client) ChatStream(ctx context.Context, req ChatRequest, onChunk func(*
 	endpoint := fmt.Sprintf("%s/api/chat", c.baseURL)
 	req.Stream = true
 
-	b, _ := json.Marshal(req)
+	b, _ := json.Marshal(req.wire())
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
 	if err != nil {
 		logger.Errorf("ollama.ChatStream NewRequestWithContext error: %v", err)
@@ -11,6 +11,18 @@ type ToolFunction struct {
 	Arguments json.RawMessage `json:"arguments"` // 这里可能是JSON，也可能是字符串里套JSON
 }
 
+// ArgumentsString 以字符串形式返回调用参数（OpenAI 规范的 arguments 为 JSON 字符串）
+func (f ToolFunction) ArgumentsString() string {
+	if len(f.Arguments) == 0 {
+		return "{}"
+	}
+	var s string
+	if err := json.Unmarshal(f.Arguments, &s); err == nil {
+		return s
+	}
+	return string(f.Arguments)
+}
+
 // ToolCall 工具调用
 type ToolCall struct {
 	ID       string       `json:"id,omitempty"`
@@ -20,11 +32,14 @@ type ToolCall struct {
 
 // Message 对话消息
 type Message struct {
-	Role      string     `json:"role"`                 // "system"(初始化AI风格) | "user" | "assistant" | "tool"(工具结果回填给模型时使用)
-	Content   string     `json:"content,omitempty"`    // 对话文本 | 工具结果
-	Images    []string   `json:"images,omitempty"`     // 多模态... 后面再说
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"` // 模型(assistant)需要调用的工具列表
-	ToolName  string     `json:"tool_name,omitempty"`  // 回填工具执行结果时带上,对应 ToolCall.Function.Name,声明这是哪个工具的结果
+	Role       string     `json:"role"`                   // "system"(初始化AI风格) | "user" | "assistant" | "tool"(工具结果回填给模型时使用)
+	Content    string     `json:"content,omitempty"`      // 对话文本 | 工具结果
+	Images     []string   `json:"images,omitempty"`       // 多模态... 后面再说
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // 模型(assistant)需要调用的工具列表
+	ToolName   string     `json:"tool_name,omitempty"`    // 回填工具执行结果时带上,对应 ToolCall.Function.Name,声明这是哪个工具的结果
+	ToolCallID string     `json:"tool_call_id,omitempty"` // 回填工具执行结果时带上,对应 ToolCall.ID（OpenAI 规范必填）
+	Status     string     `json:"status,omitempty"`       // 仅用于存储，非空表示该轮对话未正常结束，如 cancelled
+	ToolStatus string     `json:"tool_status,omitempty"`  // 仅用于存储，tool 消息对应调用的结果分类，见 constant.ToolOutcome*
 }
 
 type ChatRequest struct {
@@ -37,6 +52,17 @@ type ChatRequest struct {
 	Format    any              `json:"format,omitempty"`     // 需要结构化输出时，可以给一个 JSON Schema；模型会尽量按这个结构生成
 }
 
+// wire 发给 /api/chat 的请求：去掉消息中仅用于存储的字段（Status、ToolStatus），不修改原请求
+func (r ChatRequest) wire() ChatRequest {
+	msgs := make([]Message, len(r.Messages))
+	for i, m := range r.Messages {
+		m.Status, m.ToolStatus = "", ""
+		msgs[i] = m
+	}
+	r.Messages = msgs
+	return r
+}
+
 type ChatResponse struct {
 	Model         string  `json:"model"`          // AiProvider 模型名 如 qwen3:4b
 	CreatedAt     string  `json:"created_at"`     // 响应时间
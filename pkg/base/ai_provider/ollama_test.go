@@ -0,0 +1,24 @@
+package ai_provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestChatRequestWire(t *testing.T) {
+	Convey("Test ChatRequest wire", t, func() {
+		req := ChatRequest{Model: "qwen3:4b", Messages: []Message{
+			{Role: "assistant", Content: "partial", Status: "cancelled"},
+			{Role: "tool", Content: "ok", ToolName: "fs_cat", ToolStatus: "ok"},
+		}}
+		b, err := json.Marshal(req.wire())
+		So(err, ShouldBeNil)
+		So(string(b), ShouldNotContainSubstring, "status")
+		So(string(b), ShouldContainSubstring, `"tool_name":"fs_cat"`)
+		// 存储中的记录保留这些字段
+		So(req.Messages[0].Status, ShouldEqual, "cancelled")
+		So(req.Messages[1].ToolStatus, ShouldEqual, "ok")
+	})
+}
@@ -2,8 +2,12 @@ package base
 
 import (
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/conversation_store"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/registry"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/stream_buffer"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_approval"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_cache"
 	"sync"
 )
 
@@ -15,10 +19,14 @@ var (
 // ClientSet storage various client objects
 // Notice: some or all of them maybe nil, we should check obj when use
 type ClientSet struct {
-	MCPCli           *mcp_client.MCPClient
-	AiProviderCli    *ai_provider.Client
-	RegistryResolver registry.Resolver
-	cleanups         []func()
+	MCPCli            *mcp_client.Pool
+	AiProviderCli     *ai_provider.Client
+	RegistryResolver  registry.Resolver
+	ConversationStore conversation_store.ConversationStore
+	StreamBuffer      *stream_buffer.StreamBuffer
+	ToolApprovals     *tool_approval.Registry
+	ToolCache         *tool_cache.Cache // 未启用工具结果缓存时为 nil
+	cleanups          []func()
 }
 
 type Option func(clientSet *ClientSet)
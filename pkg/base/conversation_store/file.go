@@ -0,0 +1,185 @@
+package conversation_store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore 文件存储：每个会话一个 JSON 文件，写入时先写临时文件再 rename，保证单个文件不会写坏
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStore 以 dir 为根目录创建文件存储，目录不存在时自动创建
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("conversation_store.NewFileStore: dir is empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("conversation_store.NewFileStore: mkdir %s: %w", dir, err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Create(_ context.Context, c Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.load(c.ID)
+	if err != nil || r != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	c.MessageCount = 0
+	c.CreatedAt, c.UpdatedAt = now, now
+	return s.save(&record{Conversation: c})
+}
+
+func (s *FileStore) Info(_ context.Context, id string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.load(id)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	c := r.summary()
+	return &c, nil
+}
+
+func (s *FileStore) Get(_ context.Context, id string) ([]ai_provider.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.Messages == nil {
+		return []ai_provider.Message{}, nil
+	}
+	return r.Messages, nil
+}
+
+func (s *FileStore) Append(_ context.Context, id string, msgs ...ai_provider.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UnixMilli()
+	r, err := s.load(id)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		r = &record{Conversation: Conversation{ID: id, CreatedAt: now}}
+	}
+	r.Messages = append(r.Messages, msgs...)
+	r.UpdatedAt = now
+	return s.save(r)
+}
+
+func (s *FileStore) List(_ context.Context) ([]Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("conversation_store.List: read dir: %w", err)
+	}
+	out := make([]Conversation, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		r, err := s.read(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r.summary())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt > out[j].UpdatedAt })
+	return out, nil
+}
+
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("conversation_store.Delete: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Truncate(_ context.Context, id string, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.load(id)
+	if err != nil || r == nil {
+		return err
+	}
+	r.truncate(keep)
+	r.UpdatedAt = time.Now().UnixMilli()
+	return s.save(r)
+}
+
+func (s *FileStore) Rename(_ context.Context, id string, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.load(id)
+	if err != nil || r == nil {
+		return err
+	}
+	r.Title = title
+	r.UpdatedAt = time.Now().UnixMilli()
+	return s.save(r)
+}
+
+// path 会话 id 可能来自客户端且长度不定，取 sha256 作为定长文件名，避免路径穿越和文件名超长
+func (s *FileStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load 读取会话，不存在时返回 nil, nil
+func (s *FileStore) load(id string) (*record, error) {
+	r, err := s.read(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return r, err
+}
+
+func (s *FileStore) read(p string) (*record, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	r := new(record)
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("conversation_store: decode %s: %w", p, err)
+	}
+	return r, nil
+}
+
+func (s *FileStore) save(r *record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("conversation_store: encode %s: %w", r.ID, err)
+	}
+	p := s.path(r.ID)
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("conversation_store: write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("conversation_store: rename %s: %w", tmp, err)
+	}
+	return nil
+}
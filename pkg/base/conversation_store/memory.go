@@ -0,0 +1,110 @@
+package conversation_store
+
+import (
+	"context"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore 内存存储，重启后丢失
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]*record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]*record)}
+}
+
+func (s *MemoryStore) Create(_ context.Context, c Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[c.ID]; ok {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	c.MessageCount = 0
+	c.CreatedAt, c.UpdatedAt = now, now
+	s.items[c.ID] = &record{Conversation: c}
+	return nil
+}
+
+func (s *MemoryStore) Info(_ context.Context, id string) (*Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.items[id]
+	if !ok {
+		return nil, nil
+	}
+	c := r.summary()
+	return &c, nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) ([]ai_provider.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.items[id]
+	if !ok {
+		return []ai_provider.Message{}, nil
+	}
+	// 返回副本，避免调用方修改内部状态
+	return append([]ai_provider.Message{}, r.Messages...), nil
+}
+
+func (s *MemoryStore) Append(_ context.Context, id string, msgs ...ai_provider.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UnixMilli()
+	r, ok := s.items[id]
+	if !ok {
+		r = &record{Conversation: Conversation{ID: id, CreatedAt: now}}
+		s.items[id] = r
+	}
+	r.Messages = append(r.Messages, msgs...)
+	r.UpdatedAt = now
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context) ([]Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Conversation, 0, len(s.items))
+	for _, r := range s.items {
+		out = append(out, r.summary())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt > out[j].UpdatedAt })
+	return out, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, id)
+	return nil
+}
+
+func (s *MemoryStore) Truncate(_ context.Context, id string, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.items[id]
+	if !ok {
+		return nil
+	}
+	r.truncate(keep)
+	r.UpdatedAt = time.Now().UnixMilli()
+	return nil
+}
+
+func (s *MemoryStore) Rename(_ context.Context, id string, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.items[id]
+	if !ok {
+		return nil
+	}
+	r.Title = title
+	r.UpdatedAt = time.Now().UnixMilli()
+	return nil
+}
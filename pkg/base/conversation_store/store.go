@@ -0,0 +1,60 @@
+package conversation_store
+
+import (
+	"context"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+)
+
+// ConversationStore 对话历史存储
+// 以会话 id 为键保存 ai_provider.Message 序列，Ollama 原生与 OpenAI 兼容两条链路共用
+type ConversationStore interface {
+	// Create 创建会话，会话已存在时不做任何修改
+	Create(ctx context.Context, c Conversation) error
+	// Info 获取会话概要，会话不存在时返回 nil, nil
+	Info(ctx context.Context, id string) (*Conversation, error)
+	// Get 获取会话完整历史，会话不存在时返回空切片
+	Get(ctx context.Context, id string) ([]ai_provider.Message, error)
+	// Append 在会话末尾追加消息，会话不存在时自动创建
+	Append(ctx context.Context, id string, msgs ...ai_provider.Message) error
+	// List 列出全部会话（按最近更新时间倒序）
+	List(ctx context.Context) ([]Conversation, error)
+	// Delete 删除会话，会话不存在时不报错
+	Delete(ctx context.Context, id string) error
+	// Truncate 只保留会话最近的 keep 条消息，keep<=0 时清空历史
+	Truncate(ctx context.Context, id string, keep int) error
+	// Rename 修改会话标题，会话不存在时不报错
+	Rename(ctx context.Context, id string, title string) error
+}
+
+// Conversation 会话概要信息
+type Conversation struct {
+	ID           string `json:"id"`
+	Title        string `json:"title,omitempty"`
+	UserID       string `json:"user_id,omitempty"` // 为空表示不限定用户
+	MessageCount int    `json:"message_count"`
+	CreatedAt    int64  `json:"created_at"` // unix 毫秒
+	UpdatedAt    int64  `json:"updated_at"` // unix 毫秒
+}
+
+// record 是单个会话在存储中的完整形态
+type record struct {
+	Conversation
+	Messages []ai_provider.Message `json:"messages"`
+}
+
+func (r *record) summary() Conversation {
+	c := r.Conversation
+	c.MessageCount = len(r.Messages)
+	return c
+}
+
+// truncate 保留最近 keep 条消息
+func (r *record) truncate(keep int) {
+	if keep <= 0 {
+		r.Messages = nil
+		return
+	}
+	if len(r.Messages) > keep {
+		r.Messages = append([]ai_provider.Message(nil), r.Messages[len(r.Messages)-keep:]...)
+	}
+}
@@ -0,0 +1,86 @@
+package conversation_store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+)
+
+func testStore(t *testing.T, name string, s ConversationStore) {
+	ctx := context.Background()
+
+	Convey("Test "+name, t, func() {
+		msgs, err := s.Get(ctx, "missing")
+		So(err, ShouldBeNil)
+		So(msgs, ShouldBeEmpty)
+
+		info, err := s.Info(ctx, "a")
+		So(err, ShouldBeNil)
+		So(info, ShouldBeNil)
+		So(s.Create(ctx, Conversation{ID: "a", UserID: "u1"}), ShouldBeNil)
+		So(s.Create(ctx, Conversation{ID: "a", UserID: "u2"}), ShouldBeNil)
+		info, err = s.Info(ctx, "a")
+		So(err, ShouldBeNil)
+		So(info.UserID, ShouldEqual, "u1")
+		So(s.Rename(ctx, "a", "greeting"), ShouldBeNil)
+		So(s.Rename(ctx, "missing", "x"), ShouldBeNil)
+		info, err = s.Info(ctx, "a")
+		So(err, ShouldBeNil)
+		So(info.Title, ShouldEqual, "greeting")
+
+		So(s.Append(ctx, "a", ai_provider.Message{Role: "user", Content: "hi"}), ShouldBeNil)
+		So(s.Append(ctx, "a",
+			ai_provider.Message{Role: "assistant", ToolCalls: []ai_provider.ToolCall{{ID: "call_1", Function: ai_provider.ToolFunction{Name: "time_now", Arguments: []byte(`{}`)}}}},
+			ai_provider.Message{Role: "tool", ToolName: "time_now", ToolCallID: "call_1", Content: "now"},
+		), ShouldBeNil)
+		So(s.Append(ctx, "../b", ai_provider.Message{Role: "user", Content: "other"}), ShouldBeNil)
+
+		msgs, err = s.Get(ctx, "a")
+		So(err, ShouldBeNil)
+		So(len(msgs), ShouldEqual, 3)
+		So(msgs[1].ToolCalls[0].ID, ShouldEqual, "call_1")
+		So(msgs[2].ToolCallID, ShouldEqual, "call_1")
+
+		list, err := s.List(ctx)
+		So(err, ShouldBeNil)
+		So(len(list), ShouldEqual, 2)
+
+		So(s.Truncate(ctx, "a", 1), ShouldBeNil)
+		msgs, err = s.Get(ctx, "a")
+		So(err, ShouldBeNil)
+		So(len(msgs), ShouldEqual, 1)
+		So(msgs[0].Role, ShouldEqual, "tool")
+
+		So(s.Delete(ctx, "a"), ShouldBeNil)
+		So(s.Delete(ctx, "a"), ShouldBeNil)
+		list, err = s.List(ctx)
+		So(err, ShouldBeNil)
+		So(len(list), ShouldEqual, 1)
+		So(list[0].ID, ShouldEqual, "../b")
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, "MemoryStore", NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, "FileStore", s)
+
+	Convey("Test FileStore long id", t, func() {
+		ctx := context.Background()
+		id := strings.Repeat("x", 1024)
+		So(s.Append(ctx, id, ai_provider.Message{Role: "user", Content: "hi"}), ShouldBeNil)
+		msgs, err := s.Get(ctx, id)
+		So(err, ShouldBeNil)
+		So(msgs, ShouldHaveLength, 1)
+	})
+}
@@ -2,131 +2,168 @@ package mcp_client
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
 	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
 	mcpc "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/openai/openai-go/v2"
-	"github.com/openai/openai-go/v2/packages/param"
-	"time"
+	"sync"
+	"sync/atomic"
 )
 
+// MCPClient 与单个 MCP Server 的连接
 type MCPClient struct {
-	Client *mcpc.Client
-	Tools  []mcp.Tool
+	Name      string // 配置中的 server 名称
+	Transport string
+	Client    *mcpc.Client
+
+	mu    sync.RWMutex
+	tools []mcp.Tool // server 返回的原始工具定义，收到 tools/list_changed 后整体替换
+
+	refreshMu      sync.Mutex
+	onToolsChanged func() // 工具列表更新后的回调，由 Pool 设置
+
+	progress progressRegistry
+	callSeq  atomic.Uint64 // tools/call 请求ID序号，与 mcp-go 客户端自身的数字ID区分
 }
 
-// NewMCPClient 启动 MCP Server 并建立连接
-func NewMCPClient(url string) (*MCPClient, error) {
-	switch config.MCP.Transport {
-	case "stdio", "":
-		return newStdioMCPClient()
-	case "sse":
-		return newSSEMCPClientWithConn(url)
-	case "http":
-		return newHTTPMCPClientWithConn(url)
+// NewMCPClient 按配置启动或连接 MCP Server
+func NewMCPClient(cfg config.MCPServerConfig) (*MCPClient, error) {
+	var (
+		cli *MCPClient
+		err error
+	)
+	switch cfg.Transport {
+	case constant.MCPTransportStdio, "":
+		cfg.Transport = constant.MCPTransportStdio
+		cli, err = newStdioMCPClient(cfg)
+	case constant.MCPTransportSSE:
+		cli, err = newSSEMCPClientWithConn(cfg.HTTP.BaseURL)
+	case constant.MCPTransportHTTP:
+		cli, err = newHTTPMCPClientWithConn(cfg.HTTP.BaseURL)
 	default:
-		return nil, fmt.Errorf("unknown MCP transport: %s", config.MCP.Transport)
+		return nil, fmt.Errorf("unknown MCP transport: %s", cfg.Transport)
+	}
+	if err != nil {
+		return nil, err
 	}
+	cli.Name, cli.Transport = cfg.Name, cfg.Transport
+	cli.Client.OnNotification(cli.handleNotification)
+	return cli, nil
 }
 
-// ConvertToolsToOllama 转换 MCP 工具定义到 AiProvider 工具格式
-func (m *MCPClient) ConvertToolsToOllama() []map[string]any {
-	var out []map[string]any
-	for _, t := range m.Tools {
-		var params map[string]any
-		b, _ := json.Marshal(t.InputSchema)
-		_ = json.Unmarshal(b, &params)
-
-		out = append(out, map[string]any{
-			"type": "function",
-			"function": map[string]any{
-				"name":        t.Name,
-				"description": t.Description,
-				"parameters":  params,
-			},
-		})
-	}
-	return out
+// Tools 当前的工具列表
+func (m *MCPClient) Tools() []mcp.Tool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tools
 }
 
-// ConvertToolsToOpenAI 将 MCP 工具定义转换为 OpenAI Chat Completions 的 tools 参数
-func (m *MCPClient) ConvertToolsToOpenAI() []openai.ChatCompletionToolUnionParam {
-	out := make([]openai.ChatCompletionToolUnionParam, 0, len(m.Tools))
-	for _, t := range m.Tools {
-		var paramsMap map[string]any
-		if b, _ := json.Marshal(t.InputSchema); len(b) != 0 {
-			_ = json.Unmarshal(b, &paramsMap)
-		}
+// setOnToolsChanged 设置工具列表更新后的回调
+func (m *MCPClient) setOnToolsChanged(f func()) {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+	m.onToolsChanged = f
+}
 
-		var fp openai.FunctionParameters
-		if b, err := json.Marshal(paramsMap); err == nil {
-			_ = json.Unmarshal(b, &fp)
-		}
-		fn := openai.FunctionDefinitionParam{
-			Name:        t.Name,
-			Description: param.Opt[string]{Value: t.Description},
-			Parameters:  fp,
-		}
-		tool := &openai.ChatCompletionFunctionToolParam{
-			Type:     "function",
-			Function: fn,
-		}
-		out = append(out, openai.ChatCompletionToolUnionParam{
-			OfFunction: tool,
-		})
+func (m *MCPClient) handleNotification(notification mcp.JSONRPCNotification) {
+	switch notification.Method {
+	case mcp.MethodNotificationToolsListChanged:
+		// 通知在读取响应的协程中回调，需要另起协程发请求
+		go m.refreshTools()
+	case constant.MCPNotificationProgress:
+		m.progress.dispatch(notification.Params.AdditionalFields)
 	}
-	return out
 }
 
-// CallTool 调用 MCP 工具
-func (m *MCPClient) CallTool(ctx context.Context, name string, args any) (string, error) {
-	// 设置进度通知处理（这里应该用不上，是streamable HTTP的特性，太高级了）
-	m.Client.OnNotification(func(notification mcp.JSONRPCNotification) {
-		logger.Infof("Received notification: %v", notification)
-		if notification.Method == "notifications/progress" {
-			fmt.Println(notification)
-			params := notification.Params.AdditionalFields
-			progress := params["progress"].(float64)
-			total := params["total"].(float64)
-			message := params["message"].(string)
-
-			// 打印进度信息
-			fmt.Printf("Progress: %.2f%% - %s\n", (progress/total)*100, message)
-		}
-	})
+// refreshTools 重新获取工具列表并整体替换，失败时保留原列表
+func (m *MCPClient) refreshTools() {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), constant.MCPClientInitTimeout)
+	defer cancel()
+	res, err := m.Client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		logger.Errorf("refresh tools of MCP server %s: %v", m.Name, err)
+		return
+	}
+	m.mu.Lock()
+	m.tools = res.Tools
+	m.mu.Unlock()
+	logger.Infof("MCP server %s tools refreshed, %d tools", m.Name, len(res.Tools))
+	if m.onToolsChanged != nil {
+		m.onToolsChanged()
+	}
+}
 
-	res, err := m.Client.CallTool(ctx, mcp.CallToolRequest{
+// CallTool 调用 MCP 工具，onProgress 非空时携带 progressToken，调用期间的进度通知交给 onProgress
+func (m *MCPClient) CallTool(ctx context.Context, name string, args any, onProgress ProgressFunc) (*ToolResult, error) {
+	req := mcp.CallToolRequest{
 		Params: mcp.CallToolParams{
 			Name:      name,
 			Arguments: args,
-			Meta: &mcp.Meta{
-				ProgressToken: time.Now().Unix(),
-			},
 		},
-	})
+	}
+	if onProgress != nil {
+		token := m.progress.watch(m.Name, onProgress)
+		defer m.progress.unwatch(token)
+		req.Params.Meta = &mcp.Meta{ProgressToken: token}
+	}
+
+	res, err := m.callTool(ctx, req.Params)
 	if err != nil {
 		logger.Errorf("call tool %s: %v", name, err)
-		return "", fmt.Errorf("call tool %s: %w", name, err)
+		return nil, fmt.Errorf("call tool %s: %w", name, err)
 	}
+	return newToolResult(res), nil
+}
 
-	// 提取返回文本
-	var text string
-	for _, c := range res.Content {
-		if tc, ok := mcp.AsTextContent(c); ok {
-			text += tc.Text + "\n"
+// callTool 发送 tools/call 请求，ctx 结束（超时或取消）时通过 notifications/cancelled 通知 server 停止执行
+func (m *MCPClient) callTool(ctx context.Context, params mcp.CallToolParams) (*mcp.CallToolResult, error) {
+	id := mcp.NewRequestId(fmt.Sprintf("call-%d", m.callSeq.Add(1)))
+	resp, err := m.Client.GetTransport().SendRequest(ctx, transport.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Method:  string(mcp.MethodToolsCall),
+		Params:  params,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			go m.cancelRequest(id, context.Cause(ctx))
 		}
+		return nil, transport.NewError(err)
 	}
-	if text == "" && res.StructuredContent != nil {
-		b, _ := json.Marshal(res.StructuredContent)
-		text = string(b)
+	if resp.Error != nil {
+		return nil, resp.Error.AsError()
 	}
-	if text == "" {
-		text = "(no content)"
+	return mcp.ParseCallToolResult(&resp.Result)
+}
+
+// cancelRequest 通知 server 取消仍在执行的请求，失败只记录日志
+func (m *MCPClient) cancelRequest(id mcp.RequestId, reason error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constant.MCPClientInitTimeout)
+	defer cancel()
+	err := m.Client.GetTransport().SendNotification(ctx, mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: constant.MCPNotificationCancelled,
+			Params: mcp.NotificationParams{AdditionalFields: map[string]any{
+				"requestId": id,
+				"reason":    reason.Error(),
+			}},
+		},
+	})
+	if err != nil {
+		logger.Warnf("send cancel of request %s to MCP server %s: %v", id.String(), m.Name, err)
 	}
-	return text, nil
+}
+
+// Ping 检查连接是否可用
+func (m *MCPClient) Ping(ctx context.Context) error {
+	return m.Client.Ping(ctx)
 }
 
 // Close 关闭连接
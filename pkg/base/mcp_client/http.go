@@ -5,22 +5,29 @@ import (
 	"fmt"
 	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
 	mcpc "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // newSSEMCPClientWithConn [MCP规范已废弃]通过 SSE 连接指定 URL
-func newSSEMCPClientWithConn(url string) (*MCPClient, error) {
-	c, err := mcpc.NewStreamableHttpClient(url)
+// 连接失败时关闭客户端，避免后台重连时每次失败都遗留一个监听协程
+func newSSEMCPClientWithConn(url string) (_ *MCPClient, err error) {
+	c, err := mcpc.NewStreamableHttpClient(url, transport.WithContinuousListening())
 	if err != nil {
 		return nil, fmt.Errorf("new sse client: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			_ = c.Close()
+		}
+	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), constant.MCPClientInitTimeout)
-	defer cancel()
-
-	if err := c.Start(ctx); err != nil {
+	// Start 的 ctx 决定服务端通知监听的生命周期，不能使用初始化的超时 ctx
+	if err = c.Start(context.Background()); err != nil {
 		return nil, fmt.Errorf("sse start: %w", err)
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), constant.MCPClientInitTimeout)
+	defer cancel()
 	_, err = c.Initialize(ctx, mcp.InitializeRequest{
 		Params: mcp.InitializeParams{
 			ClientInfo: mcp.Implementation{Name: "mcp-host", Version: "0.1.0"},
@@ -35,22 +42,28 @@ func newSSEMCPClientWithConn(url string) (*MCPClient, error) {
 		return nil, fmt.Errorf("list tools: %w", err)
 	}
 
-	return &MCPClient{Client: c, Tools: res.Tools}, nil
+	return &MCPClient{Client: c, tools: res.Tools}, nil
 }
 
 // newHTTPMCPClientWithConn 通过 Streamable HTTP 连接指定 URL
-func newHTTPMCPClientWithConn(url string) (*MCPClient, error) {
-	c, err := mcpc.NewStreamableHttpClient(url)
+// 连接失败时关闭客户端，避免后台重连时每次失败都遗留一个监听协程
+func newHTTPMCPClientWithConn(url string) (_ *MCPClient, err error) {
+	c, err := mcpc.NewStreamableHttpClient(url, transport.WithContinuousListening())
 	if err != nil {
 		return nil, fmt.Errorf("new http client: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			_ = c.Close()
+		}
+	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), constant.MCPClientInitTimeout)
-	defer cancel()
-
-	if err := c.Start(ctx); err != nil {
+	// Start 的 ctx 决定服务端通知监听的生命周期，不能使用初始化的超时 ctx
+	if err = c.Start(context.Background()); err != nil {
 		return nil, fmt.Errorf("http start: %w", err)
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), constant.MCPClientInitTimeout)
+	defer cancel()
 	_, err = c.Initialize(ctx, mcp.InitializeRequest{
 		Params: mcp.InitializeParams{
 			ClientInfo: mcp.Implementation{Name: "mcp-host", Version: "0.1.0"},
@@ -65,5 +78,5 @@ func newHTTPMCPClientWithConn(url string) (*MCPClient, error) {
 		return nil, fmt.Errorf("list tools: %w", err)
 	}
 
-	return &MCPClient{Client: c, Tools: res.Tools}, nil
+	return &MCPClient{Client: c, tools: res.Tools}, nil
 }
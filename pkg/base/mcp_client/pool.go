@@ -0,0 +1,309 @@
+package mcp_client
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Pool 聚合多个 MCP Server：合并各 server 的工具并按工具名把调用路由到所属的 server
+// 多个 server 时工具名为 "<server>__<tool>"，避免不同 server 的同名工具冲突
+type Pool struct {
+	prefix bool
+
+	rebuildMu sync.Mutex
+	mu        sync.RWMutex
+	servers   []*server
+	tools     []mcp.Tool           // 合并后的工具，名称已加前缀
+	routes    map[string]toolRoute // 合并后的工具名 -> 所属 server 与原始工具名
+}
+
+type toolRoute struct {
+	server *server
+	name   string
+	retry  bool // 只读或幂等的工具，连接断开重连后可以重试一次
+}
+
+// ServerStatus MCP Server 的健康状态
+type ServerStatus struct {
+	Name      string
+	Transport string
+	Healthy   bool
+	Tools     []string // 该 server 提供的工具（合并后的名称）
+	Error     string
+	CheckedAt time.Time
+}
+
+// NewPool 连接所有配置的 MCP Server，prefix 为 true 时工具名加上 server 名前缀
+// 部分 server 连接失败不影响其余 server，失败原因可通过 Health 查看；全部失败时返回错误
+func NewPool(cfgs []config.MCPServerConfig, prefix bool) (*Pool, error) {
+	if len(cfgs) == 0 {
+		return nil, fmt.Errorf("no MCP server configured")
+	}
+	p := &Pool{prefix: prefix}
+	seen := make(map[string]bool, len(cfgs))
+	for _, cfg := range cfgs {
+		if prefix && cfg.Name == "" {
+			return nil, fmt.Errorf("MCP server name is required when multiple servers are configured")
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("duplicate MCP server name: %s", cfg.Name)
+		}
+		seen[cfg.Name] = true
+	}
+	for _, cfg := range cfgs {
+		p.servers = append(p.servers, newServer(cfg, p.rebuild))
+	}
+	var failed []*server
+	var firstErr error
+	for _, s := range p.servers {
+		if err := s.connect(nil); err != nil {
+			logger.Errorf("connect MCP server %s: %v", s.cfg.Name, err)
+			failed = append(failed, s)
+			firstErr = cmp.Or(firstErr, err)
+		}
+	}
+	if len(failed) == len(p.servers) {
+		return nil, fmt.Errorf("all MCP servers failed to connect: %w", firstErr)
+	}
+	// 启动时连接失败的 server 在后台继续重试
+	for _, s := range failed {
+		s.supervise()
+	}
+	p.rebuild()
+	return p, nil
+}
+
+// rebuild 根据各 server 当前的工具重新生成合并后的工具列表与路由表，整体替换，
+// 已开始的对话继续使用旧列表，下一轮对话使用新列表
+func (p *Pool) rebuild() {
+	p.rebuildMu.Lock()
+	defer p.rebuildMu.Unlock()
+
+	var tools []mcp.Tool
+	routes := make(map[string]toolRoute)
+	for _, s := range p.servers {
+		cli, err := s.client()
+		if err != nil {
+			continue
+		}
+		for _, t := range cli.Tools() {
+			name := p.toolName(s.cfg.Name, t.Name)
+			if r, ok := routes[name]; ok {
+				logger.Errorf("MCP tool %s of server %s conflicts with server %s, ignored", t.Name, s.cfg.Name, r.server.cfg.Name)
+				continue
+			}
+			routes[name] = toolRoute{server: s, name: t.Name, retry: IsIdempotent(t)}
+			t.Name = name
+			tools = append(tools, t)
+		}
+	}
+	p.mu.Lock()
+	p.tools, p.routes = tools, routes
+	p.mu.Unlock()
+}
+
+func (p *Pool) toolName(serverName, tool string) string {
+	if !p.prefix {
+		return tool
+	}
+	return serverName + constant.MCPToolNameSeparator + tool
+}
+
+// Tools 合并后的全部工具
+func (p *Pool) Tools() []mcp.Tool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.tools
+}
+
+// Tool 按合并后的工具名查找工具
+func (p *Pool) Tool(name string) (mcp.Tool, bool) {
+	for _, t := range p.Tools() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return mcp.Tool{}, false
+}
+
+// filterTools 按白名单过滤工具，白名单为空时返回全部
+func (p *Pool) filterTools(allow []string) []mcp.Tool {
+	tools := p.Tools()
+	if len(allow) == 0 {
+		return tools
+	}
+	out := make([]mcp.Tool, 0, len(allow))
+	for _, t := range tools {
+		if slices.Contains(allow, t.Name) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ConvertToolsToOllama 转换 MCP 工具定义到 AiProvider 工具格式，allow 非空时只转换其中的工具
+func (p *Pool) ConvertToolsToOllama(allow ...string) []map[string]any {
+	var out []map[string]any
+	for _, t := range p.filterTools(allow) {
+		var params map[string]any
+		b, _ := json.Marshal(t.InputSchema)
+		_ = json.Unmarshal(b, &params)
+
+		out = append(out, map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  params,
+			},
+		})
+	}
+	return out
+}
+
+// ConvertToolsToOpenAI 将 MCP 工具定义转换为 OpenAI Chat Completions 的 tools 参数，allow 非空时只转换其中的工具
+func (p *Pool) ConvertToolsToOpenAI(allow ...string) []openai.ChatCompletionToolUnionParam {
+	tools := p.filterTools(allow)
+	out := make([]openai.ChatCompletionToolUnionParam, 0, len(tools))
+	for _, t := range tools {
+		var paramsMap map[string]any
+		if b, _ := json.Marshal(t.InputSchema); len(b) != 0 {
+			_ = json.Unmarshal(b, &paramsMap)
+		}
+
+		var fp openai.FunctionParameters
+		if b, err := json.Marshal(paramsMap); err == nil {
+			_ = json.Unmarshal(b, &fp)
+		}
+		fn := openai.FunctionDefinitionParam{
+			Name:        t.Name,
+			Description: param.Opt[string]{Value: t.Description},
+			Parameters:  fp,
+		}
+		tool := &openai.ChatCompletionFunctionToolParam{
+			Type:     "function",
+			Function: fn,
+		}
+		out = append(out, openai.ChatCompletionToolUnionParam{
+			OfFunction: tool,
+		})
+	}
+	return out
+}
+
+// CallTool 按合并后的工具名找到所属 server 并调用，onProgress 接收本次调用的进度通知，可为空
+// 超过工具的调用超时时取消调用并返回 *TimeoutError
+func (p *Pool) CallTool(ctx context.Context, name string, args any, onProgress ProgressFunc) (*ToolResult, error) {
+	p.mu.RLock()
+	r, ok := p.routes[name]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+
+	timeout := callTimeout(name)
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res, err := p.call(callCtx, r, args, onProgress)
+	// 仅本次调用的超时计为超时，调用方取消时原样返回
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return nil, &TimeoutError{Tool: name, Timeout: timeout}
+	}
+	return res, err
+}
+
+// call 在 server 的当前连接上调用工具
+// 连接断开时重新连接，只读/幂等的工具或请求未送达（会话过期）时在新连接上重试一次
+func (p *Pool) call(ctx context.Context, r toolRoute, args any, onProgress ProgressFunc) (*ToolResult, error) {
+	cli, err := r.server.client()
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.CallTool(ctx, r.name, args, onProgress)
+	if !isConnError(ctx, err) {
+		return res, err
+	}
+	if rerr := r.server.reconnect(cli); rerr != nil {
+		return nil, err
+	}
+	if !r.retry && !errors.Is(err, transport.ErrSessionTerminated) {
+		return nil, err
+	}
+	if cli, rerr := r.server.client(); rerr == nil {
+		logger.Infof("retry tool %s on MCP server %s", r.name, r.server.cfg.Name)
+		return cli.CallTool(ctx, r.name, args, onProgress)
+	}
+	return nil, err
+}
+
+// IsReadOnly 工具声明为只读
+func IsReadOnly(t mcp.Tool) bool {
+	return t.Annotations.ReadOnlyHint != nil && *t.Annotations.ReadOnlyHint
+}
+
+// IsIdempotent 工具声明为只读或幂等
+func IsIdempotent(t mcp.Tool) bool {
+	return IsReadOnly(t) || (t.Annotations.IdempotentHint != nil && *t.Annotations.IdempotentHint)
+}
+
+// Health 检查各 server 的连接状态，按配置顺序返回
+func (p *Pool) Health(ctx context.Context) []ServerStatus {
+	p.mu.RLock()
+	tools := make(map[*server][]string)
+	for name, r := range p.routes {
+		tools[r.server] = append(tools[r.server], name)
+	}
+	p.mu.RUnlock()
+
+	out := make([]ServerStatus, len(p.servers))
+	var wg sync.WaitGroup
+	for i, s := range p.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cli, err := s.client()
+			if err == nil {
+				pingCtx, cancel := context.WithTimeout(ctx, constant.MCPClientInitTimeout)
+				err = cli.Ping(pingCtx)
+				cancel()
+				if isConnError(ctx, err) {
+					go func() { _ = s.reconnect(cli) }()
+				}
+			}
+			status := ServerStatus{
+				Name:      s.cfg.Name,
+				Transport: s.cfg.Transport,
+				Healthy:   err == nil,
+				Tools:     tools[s],
+				CheckedAt: time.Now(),
+			}
+			if err != nil {
+				status.Error = err.Error()
+			}
+			slices.Sort(status.Tools)
+			out[i] = status
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// Close 关闭所有连接
+func (p *Pool) Close() {
+	for _, s := range p.servers {
+		s.close()
+	}
+}
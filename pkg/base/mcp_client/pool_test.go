@@ -0,0 +1,307 @@
+package mcp_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+)
+
+// newTestServer 启动一个提供 echo 工具的 Streamable HTTP MCP Server，echo 返回 server 名
+func newTestServer(name string) (*mcpserver.MCPServer, *httptest.Server) {
+	s := newTestCore(name)
+	return s, httptest.NewServer(mcpserver.NewStreamableHTTPServer(s))
+}
+
+func newTestCore(name string) *mcpserver.MCPServer {
+	s := mcpserver.NewMCPServer(name, "test", mcpserver.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("echo", mcp.WithIdempotentHintAnnotation(true)), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(name + ":" + req.Params.Name), nil
+	})
+	s.AddTool(mcp.NewTool("steps"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req.Params.Meta != nil && req.Params.Meta.ProgressToken != nil {
+			srv := mcpserver.ServerFromContext(ctx)
+			for i := 1; i <= 2; i++ {
+				_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+					"progressToken": req.Params.Meta.ProgressToken,
+					"progress":      i,
+					"total":         2,
+				})
+				// 通知异步写出，留出时间避免响应先于通知返回
+				time.Sleep(20 * time.Millisecond)
+			}
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	s.AddTool(mcp.NewTool("slow"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+		return mcp.NewToolResultText("slow"), nil
+	})
+	s.AddResource(mcp.NewResource("test://"+name, name), func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/plain", Text: name}}, nil
+	})
+	s.AddResourceTemplate(mcp.NewResourceTemplate("echo://{text}", "echo"), func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		text, _ := req.Params.Arguments["text"].([]string)
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, Text: name + ":" + strings.Join(text, "")}}, nil
+	})
+	return s
+}
+
+// stopTestServer 关闭 MCP Server，返回其监听地址
+func stopTestServer(ts *httptest.Server) string {
+	addr := ts.Listener.Addr().String()
+	ts.CloseClientConnections()
+	ts.Close()
+	return addr
+}
+
+// startTestServerAt 在指定地址重新启动 MCP Server
+func startTestServerAt(addr, name string) *httptest.Server {
+	l, err := net.Listen("tcp", addr)
+	So(err, ShouldBeNil)
+	next := httptest.NewUnstartedServer(mcpserver.NewStreamableHTTPServer(newTestCore(name)))
+	_ = next.Listener.Close()
+	next.Listener = l
+	next.Start()
+	return next
+}
+
+func httpServer(name, url string) config.MCPServerConfig {
+	cfg := config.MCPServerConfig{Name: name, Transport: "http"}
+	cfg.HTTP.BaseURL = url
+	return cfg
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test Pool", t, func() {
+		coreA, a := newTestServer("a")
+		_, b := newTestServer("b")
+		p, err := NewPool([]config.MCPServerConfig{
+			httpServer("a", a.URL),
+			httpServer("b", b.URL),
+			httpServer("down", "http://127.0.0.1:1/mcp"),
+		}, true)
+		So(err, ShouldBeNil)
+		Reset(func() {
+			p.Close()
+			a.CloseClientConnections()
+			a.Close()
+			b.Close()
+		})
+
+		Convey("prefix and route", func() {
+			var names []string
+			for _, t := range p.Tools() {
+				names = append(names, t.Name)
+			}
+			So(names, ShouldResemble, []string{"a__echo", "a__slow", "a__steps", "b__echo", "b__slow", "b__steps"})
+
+			res, err := p.CallTool(ctx, "b__echo", nil, nil)
+			So(err, ShouldBeNil)
+			So(res.Text, ShouldEqual, "b:echo\n")
+			_, err = p.CallTool(ctx, "echo", nil, nil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("progress", func() {
+			var got []Progress
+			res, err := p.CallTool(ctx, "a__steps", nil, func(pg Progress) { got = append(got, pg) })
+			So(err, ShouldBeNil)
+			So(res.Text, ShouldEqual, "done\n")
+			So(got, ShouldResemble, []Progress{{Progress: 1, Total: 2}, {Progress: 2, Total: 2}})
+
+			// 不关心进度时不携带 progressToken
+			_, err = p.CallTool(ctx, "a__steps", nil, nil)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("timeout", func() {
+			cancelled := make(chan string, 1)
+			coreA.AddNotificationHandler(constant.MCPNotificationCancelled, func(ctx context.Context, n mcp.JSONRPCNotification) {
+				cancelled <- fmt.Sprint(n.Params.AdditionalFields["requestId"])
+			})
+			cfg := new(config.Config)
+			cfg.MCP.CallTimeout = time.Minute
+			cfg.MCP.ToolTimeouts = map[string]time.Duration{"a__slow": 100 * time.Millisecond}
+			config.MCP = &cfg.MCP
+			Reset(func() { config.MCP = nil })
+
+			start := time.Now()
+			_, err := p.CallTool(ctx, "a__slow", nil, nil)
+			var te *TimeoutError
+			So(errors.As(err, &te), ShouldBeTrue)
+			So(te.Tool, ShouldEqual, "a__slow")
+			So(te.Timeout, ShouldEqual, 100*time.Millisecond)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+
+			// 超时后通知 server 取消该请求
+			select {
+			case id := <-cancelled:
+				So(id, ShouldStartWith, "call-")
+			case <-time.After(time.Second):
+				So("cancelled notification not received", ShouldBeEmpty)
+			}
+
+			// 调用方取消时原样返回，不计为超时
+			cctx, cancel := context.WithCancel(ctx)
+			time.AfterFunc(50*time.Millisecond, cancel)
+			_, err = p.CallTool(cctx, "a__slow", nil, nil)
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			So(errors.As(err, &te), ShouldBeFalse)
+		})
+
+		Convey("resources", func() {
+			resources, templates := p.Resources(ctx)
+			So(resources, ShouldResemble, []Resource{{Server: "a", URI: "test://a", Name: "a"}, {Server: "b", URI: "test://b", Name: "b"}})
+			So(templates, ShouldResemble, []ResourceTemplate{{Server: "a", URITemplate: "echo://{text}", Name: "echo"}, {Server: "b", URITemplate: "echo://{text}", Name: "echo"}})
+
+			contents, err := p.ReadResource(ctx, "b", "test://b")
+			So(err, ShouldBeNil)
+			So(contents, ShouldResemble, []Content{{Type: ContentTypeResource, URI: "test://b", MIMEType: "text/plain", Text: "b"}})
+			contents, err = p.ReadResource(ctx, "a", "echo://hi")
+			So(err, ShouldBeNil)
+			So(contents[0].Text, ShouldEqual, "a:hi")
+
+			// 多个 server 时必须指定 server
+			_, err = p.ReadResource(ctx, "", "test://a")
+			So(errors.Is(err, ErrUnknownServer), ShouldBeTrue)
+			_, err = p.ReadResource(ctx, "down", "test://a")
+			So(errors.Is(err, ErrServerUnavailable), ShouldBeTrue)
+			_, err = p.ReadResource(ctx, "a", "test://none")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("refresh on tools/list_changed", func() {
+			// 等待通知监听连接建立
+			time.Sleep(200 * time.Millisecond)
+			coreA.AddTool(mcp.NewTool("time_now"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("now"), nil
+			})
+			So(waitTools(p, 7), ShouldBeTrue)
+			res, err := p.CallTool(ctx, "a__time_now", nil, nil)
+			So(err, ShouldBeNil)
+			So(res.Text, ShouldEqual, "now\n")
+		})
+
+		Convey("reconnect and retry", func() {
+			// 连接被关闭后，幂等工具在重连后重试成功
+			cli, err := p.servers[0].client()
+			So(err, ShouldBeNil)
+			_ = cli.Client.Close()
+			res, err := p.CallTool(ctx, "a__echo", nil, nil)
+			So(err, ShouldBeNil)
+			So(res.Text, ShouldEqual, "a:echo\n")
+
+			// server 停止期间调用失败且不再提供其工具，重启后后台重连，工具恢复
+			addr := stopTestServer(a)
+			_, err = p.CallTool(ctx, "a__echo", nil, nil)
+			So(err, ShouldNotBeNil)
+			So(waitTools(p, 3), ShouldBeTrue)
+			So(p.Health(ctx)[0].Healthy, ShouldBeFalse)
+			a = startTestServerAt(addr, "a")
+			So(waitTools(p, 6), ShouldBeTrue)
+			res, err = p.CallTool(ctx, "a__echo", nil, nil)
+			So(err, ShouldBeNil)
+			So(res.Text, ShouldEqual, "a:echo\n")
+		})
+
+		Convey("health", func() {
+			list := p.Health(ctx)
+			So(len(list), ShouldEqual, 3)
+			So(list[0].Healthy, ShouldBeTrue)
+			So(list[0].Tools, ShouldResemble, []string{"a__echo", "a__slow", "a__steps"})
+			So(list[2].Name, ShouldEqual, "down")
+			So(list[2].Healthy, ShouldBeFalse)
+			So(list[2].Error, ShouldNotBeEmpty)
+		})
+
+		Convey("duplicate name", func() {
+			_, err := NewPool([]config.MCPServerConfig{httpServer("a", a.URL), httpServer("a", b.URL)}, true)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestConnectFailure(t *testing.T) {
+	Convey("Test failed connect closes client", t, func() {
+		// 未声明 tools 能力的 server：Initialize 成功后 ListTools 失败
+		core := mcpserver.NewMCPServer("broken", "test")
+		handler := mcpserver.NewStreamableHTTPServer(core)
+		var live atomic.Int64 // 客户端保持着的监听连接（GET）数
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				live.Add(1)
+				defer live.Add(-1)
+			}
+			handler.ServeHTTP(w, r)
+		}))
+		defer func() {
+			ts.CloseClientConnections()
+			ts.Close()
+		}()
+
+		s := newServer(httpServer("broken", ts.URL), nil)
+		for i := 0; i < 3; i++ {
+			err := s.connect(nil)
+			So(err, ShouldNotBeNil)
+		}
+		// 监听连接在 Initialize 成功后异步建立，留出时间再检查
+		time.Sleep(200 * time.Millisecond)
+		So(live.Load(), ShouldEqual, 0)
+	})
+}
+
+func waitTools(p *Pool, n int) bool {
+	for i := 0; i < 100; i++ {
+		if len(p.Tools()) == n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestNewToolResult(t *testing.T) {
+	Convey("Test newToolResult", t, func() {
+		res := newToolResult(&mcp.CallToolResult{Content: []mcp.Content{
+			mcp.NewTextContent("chart"),
+			mcp.NewImageContent("aW1n", "image/png"),
+			mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "file:///a.txt", MIMEType: "text/plain", Text: "hello"}),
+			mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: "file:///b.jpg", MIMEType: "image/jpeg", Blob: "anBn"}),
+			mcp.NewAudioContent("YXVk", "audio/wav"),
+		}})
+		So(res.Text, ShouldEqual, "chart\n[image image/png]\n[resource file:///a.txt]\nhello\n[resource file:///b.jpg image/jpeg]\n[audio audio/wav]\n")
+		So(res.Images(), ShouldResemble, []string{"aW1n", "anBn"})
+		So(res.Contents, ShouldHaveLength, 5)
+		So(res.Contents[1], ShouldResemble, Content{Type: ContentTypeImage, MIMEType: "image/png", Data: "aW1n"})
+
+		So(res.IsError, ShouldBeFalse)
+
+		res = newToolResult(&mcp.CallToolResult{StructuredContent: map[string]any{"ok": true}})
+		So(res.Text, ShouldEqual, `{"ok":true}`)
+		So(res.Images(), ShouldBeEmpty)
+
+		res = newToolResult(mcp.NewToolResultError("bad input"))
+		So(res.IsError, ShouldBeTrue)
+		So(res.Text, ShouldEqual, "bad input\n")
+	})
+}
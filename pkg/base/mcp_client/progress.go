@@ -0,0 +1,78 @@
+package mcp_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Progress 工具调用的进度，Total 为 0 表示总量未知
+type Progress struct {
+	Progress float64
+	Total    float64
+	Message  string
+}
+
+// ProgressFunc 接收一次工具调用的进度通知
+type ProgressFunc func(Progress)
+
+// progressRegistry 按 progressToken 将 notifications/progress 分发到发起调用的一方
+type progressRegistry struct {
+	mu       sync.Mutex
+	seq      uint64
+	handlers map[string]ProgressFunc
+}
+
+// watch 为一次调用分配 progressToken，调用结束后需 unwatch
+func (r *progressRegistry) watch(prefix string, fn ProgressFunc) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handlers == nil {
+		r.handlers = make(map[string]ProgressFunc)
+	}
+	r.seq++
+	token := fmt.Sprintf("%s-%d", prefix, r.seq)
+	r.handlers[token] = fn
+	return token
+}
+
+func (r *progressRegistry) unwatch(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.handlers, token)
+}
+
+// dispatch 处理一条 notifications/progress，缺失或类型不符的字段按零值处理，未知 token 忽略
+func (r *progressRegistry) dispatch(params map[string]any) {
+	token, ok := params["progressToken"]
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	fn := r.handlers[fmt.Sprint(token)]
+	r.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	p := Progress{
+		Progress: toFloat(params["progress"]),
+		Total:    toFloat(params["total"]),
+	}
+	p.Message, _ = params["message"].(string)
+	fn(p)
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	}
+	return 0
+}
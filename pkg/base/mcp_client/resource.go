@@ -0,0 +1,142 @@
+package mcp_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrUnknownServer 没有该名称的 MCP Server
+var ErrUnknownServer = errors.New("unknown MCP server")
+
+// Resource MCP Server 提供的资源
+type Resource struct {
+	Server      string // 所属 server 的名称，读取资源时用于路由
+	URI         string
+	Name        string
+	Description string
+	MIMEType    string
+}
+
+// ResourceTemplate MCP Server 提供的资源模板，按 RFC 6570 展开 URITemplate 后即可读取
+type ResourceTemplate struct {
+	Server      string
+	URITemplate string
+	Name        string
+	Description string
+	MIMEType    string
+}
+
+// SupportsResources server 是否声明了 resources 能力
+func (m *MCPClient) SupportsResources() bool {
+	return m.Client.GetServerCapabilities().Resources != nil
+}
+
+// ListResources 列出 server 的全部资源与资源模板
+func (m *MCPClient) ListResources(ctx context.Context) ([]Resource, []ResourceTemplate, error) {
+	rs, err := m.Client.ListResources(ctx, mcp.ListResourcesRequest{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list resources: %w", err)
+	}
+	ts, err := m.Client.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list resource templates: %w", err)
+	}
+	resources := make([]Resource, 0, len(rs.Resources))
+	for _, r := range rs.Resources {
+		resources = append(resources, Resource{Server: m.Name, URI: r.URI, Name: r.Name, Description: r.Description, MIMEType: r.MIMEType})
+	}
+	templates := make([]ResourceTemplate, 0, len(ts.ResourceTemplates))
+	for _, t := range ts.ResourceTemplates {
+		tpl := ResourceTemplate{Server: m.Name, Name: t.Name, Description: t.Description, MIMEType: t.MIMEType}
+		if t.URITemplate != nil {
+			tpl.URITemplate = t.URITemplate.Raw()
+		}
+		templates = append(templates, tpl)
+	}
+	return resources, templates, nil
+}
+
+// ReadResource 读取资源，返回的内容类型均为 resource
+func (m *MCPClient) ReadResource(ctx context.Context, uri string) ([]Content, error) {
+	res, err := m.Client.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+	if err != nil {
+		return nil, fmt.Errorf("read resource %s: %w", uri, err)
+	}
+	out := make([]Content, 0, len(res.Contents))
+	for _, c := range res.Contents {
+		switch rc := c.(type) {
+		case mcp.TextResourceContents:
+			out = append(out, Content{Type: ContentTypeResource, URI: rc.URI, MIMEType: rc.MIMEType, Text: rc.Text})
+		case mcp.BlobResourceContents:
+			out = append(out, Content{Type: ContentTypeResource, URI: rc.URI, MIMEType: rc.MIMEType, Data: rc.Blob})
+		}
+	}
+	return out, nil
+}
+
+// Resources 各 server 的资源与资源模板，按配置顺序
+// 未连接或未声明 resources 能力的 server 跳过，列出失败的 server 记录日志后跳过
+func (p *Pool) Resources(ctx context.Context) ([]Resource, []ResourceTemplate) {
+	var (
+		resources []Resource
+		templates []ResourceTemplate
+	)
+	for _, s := range p.servers {
+		cli, err := s.client()
+		if err != nil || !cli.SupportsResources() {
+			continue
+		}
+		listCtx, cancel := context.WithTimeout(ctx, callTimeout(""))
+		rs, ts, err := cli.ListResources(listCtx)
+		cancel()
+		if err != nil {
+			logger.Errorf("MCP server %s: %v", s.cfg.Name, err)
+			continue
+		}
+		resources = append(resources, rs...)
+		templates = append(templates, ts...)
+	}
+	return resources, templates
+}
+
+// ReadResource 从名为 serverName 的 server 读取资源，只配置了一个 server 时 serverName 可为空
+// 连接断开时重新连接并重试一次
+func (p *Pool) ReadResource(ctx context.Context, serverName, uri string) ([]Content, error) {
+	s, err := p.serverByName(serverName)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, callTimeout(""))
+	defer cancel()
+	cli, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := cli.ReadResource(ctx, uri)
+	if !isConnError(ctx, err) {
+		return contents, err
+	}
+	if rerr := s.reconnect(cli); rerr != nil {
+		return nil, err
+	}
+	if cli, rerr := s.client(); rerr == nil {
+		return cli.ReadResource(ctx, uri)
+	}
+	return nil, err
+}
+
+// serverByName 按名称查找 server
+func (p *Pool) serverByName(name string) (*server, error) {
+	if name == "" && len(p.servers) == 1 {
+		return p.servers[0], nil
+	}
+	for _, s := range p.servers {
+		if s.cfg.Name == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownServer, name)
+}
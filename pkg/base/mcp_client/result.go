@@ -0,0 +1,88 @@
+package mcp_client
+
+import (
+	"encoding/json"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
+
+// 工具结果中内容的类型
+const (
+	ContentTypeText     = "text"
+	ContentTypeImage    = "image"
+	ContentTypeAudio    = "audio"
+	ContentTypeResource = "resource"
+)
+
+// Content 工具结果中的一项内容
+type Content struct {
+	Type     string `json:"type"`                // text | image | audio | resource
+	Text     string `json:"text,omitempty"`      // text 的文本，文本类 resource 的内容
+	MIMEType string `json:"mime_type,omitempty"` // image、audio、resource 的 MIME 类型
+	Data     string `json:"data,omitempty"`      // image、audio 与二进制 resource 的 base64 数据
+	URI      string `json:"uri,omitempty"`       // resource 的 URI
+}
+
+// ToolResult 工具调用结果
+type ToolResult struct {
+	Text     string    // 交给模型的文本：文本内容与文本类 resource 原样拼接，其他内容以占位说明代替
+	Contents []Content // 全部内容，按工具返回的顺序
+	IsError  bool      // 工具报告执行失败（CallToolResult.isError），Text 为失败原因
+}
+
+// TextResult 只有文本的结果，用于工具调用失败等由 host 生成的结果
+func TextResult(text string) *ToolResult {
+	return &ToolResult{Text: text, Contents: []Content{{Type: ContentTypeText, Text: text}}}
+}
+
+// Images 结果中的图片（含图片类型的二进制 resource），base64 编码，可直接作为 ai_provider.Message.Images
+func (r *ToolResult) Images() []string {
+	var out []string
+	for _, c := range r.Contents {
+		if c.Data != "" && (c.Type == ContentTypeImage || c.Type == ContentTypeResource && strings.HasPrefix(c.MIMEType, "image/")) {
+			out = append(out, c.Data)
+		}
+	}
+	return out
+}
+
+// newToolResult 转换 MCP 的 CallToolResult
+func newToolResult(res *mcp.CallToolResult) *ToolResult {
+	r := &ToolResult{IsError: res.IsError}
+	var text strings.Builder
+	for _, c := range res.Content {
+		switch v := c.(type) {
+		case mcp.TextContent:
+			r.Contents = append(r.Contents, Content{Type: ContentTypeText, Text: v.Text})
+			text.WriteString(v.Text + "\n")
+		case mcp.ImageContent:
+			r.Contents = append(r.Contents, Content{Type: ContentTypeImage, MIMEType: v.MIMEType, Data: v.Data})
+			text.WriteString("[image " + v.MIMEType + "]\n")
+		case mcp.AudioContent:
+			r.Contents = append(r.Contents, Content{Type: ContentTypeAudio, MIMEType: v.MIMEType, Data: v.Data})
+			text.WriteString("[audio " + v.MIMEType + "]\n")
+		case mcp.EmbeddedResource:
+			switch rc := v.Resource.(type) {
+			case mcp.TextResourceContents:
+				r.Contents = append(r.Contents, Content{Type: ContentTypeResource, URI: rc.URI, MIMEType: rc.MIMEType, Text: rc.Text})
+				text.WriteString("[resource " + rc.URI + "]\n" + rc.Text + "\n")
+			case mcp.BlobResourceContents:
+				r.Contents = append(r.Contents, Content{Type: ContentTypeResource, URI: rc.URI, MIMEType: rc.MIMEType, Data: rc.Blob})
+				text.WriteString("[resource " + rc.URI + " " + rc.MIMEType + "]\n")
+			}
+		case mcp.ResourceLink:
+			r.Contents = append(r.Contents, Content{Type: ContentTypeResource, URI: v.URI, MIMEType: v.MIMEType})
+			text.WriteString("[resource " + v.URI + "]\n")
+		}
+	}
+	r.Text = text.String()
+	if r.Text == "" && res.StructuredContent != nil {
+		b, _ := json.Marshal(res.StructuredContent)
+		r.Text = string(b)
+		r.Contents = append(r.Contents, Content{Type: ContentTypeText, Text: r.Text})
+	}
+	if r.Text == "" {
+		r.Text = "(no content)"
+	}
+	return r
+}
@@ -0,0 +1,167 @@
+package mcp_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"github.com/FantasyRL/go-mcp-demo/pkg/logger"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"sync"
+	"time"
+)
+
+// ErrServerUnavailable MCP Server 未连接（启动时连接失败或断线后尚未重连成功）
+var ErrServerUnavailable = errors.New("MCP server unavailable")
+
+// server 一个配置的 MCP Server 及其当前连接
+// 连接断开（传输错误、会话过期）后重新建立连接：重新 Initialize/ListTools，stdio 模式会重新启动子进程；
+// 单次重连失败时在后台按退避间隔持续重试，直到成功或 Pool 关闭
+type server struct {
+	cfg            config.MCPServerConfig
+	onToolsChanged func() // 连接建立或工具列表更新后的回调
+
+	mu          sync.RWMutex
+	cli         *MCPClient // 未连接时为 nil
+	err         error      // 最近一次连接失败的原因
+	supervising bool       // 是否已有后台重连
+	closed      bool
+
+	connMu sync.Mutex // 保证同一时间只有一次重连
+}
+
+func newServer(cfg config.MCPServerConfig, onToolsChanged func()) *server {
+	if cfg.Transport == "" {
+		cfg.Transport = constant.MCPTransportStdio
+	}
+	return &server{cfg: cfg, onToolsChanged: onToolsChanged}
+}
+
+// client 当前连接，未连接时返回最近一次连接失败的原因
+func (s *server) client() (*MCPClient, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.cli == nil {
+		if s.err == nil {
+			return nil, fmt.Errorf("%w: %s not connected", ErrServerUnavailable, s.cfg.Name)
+		}
+		return nil, fmt.Errorf("%w: %s: %w", ErrServerUnavailable, s.cfg.Name, s.err)
+	}
+	return s.cli, nil
+}
+
+// connect 建立新连接并替换 broken；其他调用方已完成重连时直接返回
+func (s *server) connect(broken *MCPClient) error {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	s.mu.RLock()
+	current, closed := s.cli, s.closed
+	s.mu.RUnlock()
+	if closed {
+		return fmt.Errorf("MCP server %s closed", s.cfg.Name)
+	}
+	if current != broken {
+		return nil
+	}
+
+	if broken != nil {
+		// stdio 模式下同时结束旧的子进程
+		broken.Close()
+	}
+	cli, err := NewMCPClient(s.cfg)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		if cli != nil {
+			cli.Close()
+		}
+		return fmt.Errorf("MCP server %s closed", s.cfg.Name)
+	}
+	s.cli, s.err = cli, err
+	s.mu.Unlock()
+	if cli != nil {
+		cli.setOnToolsChanged(s.onToolsChanged)
+	}
+	// 连接变化后工具列表随之变化，未连接的 server 不提供工具
+	if s.onToolsChanged != nil && (cli != nil || broken != nil) {
+		s.onToolsChanged()
+	}
+	return err
+}
+
+// reconnect 连接 broken 出错后调用：立即重连一次，失败则转入后台重试
+func (s *server) reconnect(broken *MCPClient) error {
+	logger.Warnf("MCP server %s connection lost, reconnecting", s.cfg.Name)
+	if err := s.connect(broken); err != nil {
+		logger.Errorf("reconnect MCP server %s: %v", s.cfg.Name, err)
+		s.supervise()
+		return err
+	}
+	logger.Infof("MCP server %s reconnected", s.cfg.Name)
+	return nil
+}
+
+// supervise 在后台按退避间隔重连，直到连接成功或关闭
+func (s *server) supervise() {
+	s.mu.Lock()
+	if s.supervising || s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.supervising = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			s.supervising = false
+			s.mu.Unlock()
+		}()
+		backoff := constant.MCPReconnectMinBackoff
+		for {
+			time.Sleep(backoff)
+			s.mu.RLock()
+			cli, closed := s.cli, s.closed
+			s.mu.RUnlock()
+			if closed {
+				return
+			}
+			if cli != nil {
+				// 已由其他调用方重连成功
+				return
+			}
+			if err := s.connect(nil); err == nil {
+				logger.Infof("MCP server %s reconnected", s.cfg.Name)
+				return
+			}
+			backoff = min(backoff*2, constant.MCPReconnectMaxBackoff)
+		}
+	}()
+}
+
+func (s *server) close() {
+	s.mu.Lock()
+	cli := s.cli
+	s.cli, s.closed = nil, true
+	s.mu.Unlock()
+	if cli != nil {
+		cli.Close()
+	}
+}
+
+// IsTransportError 是否为与 MCP Server 之间的通信错误：传输层错误或 server 不可用
+func IsTransportError(err error) bool {
+	var te *transport.Error
+	return errors.As(err, &te) || errors.Is(err, ErrServerUnavailable)
+}
+
+// isConnError 是否为需要重连的错误：传输层错误（连接断开、子进程退出、会话过期等），调用方取消或超时除外
+func isConnError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var te *transport.Error
+	return errors.As(err, &te)
+}
@@ -10,15 +10,21 @@ import (
 )
 
 // newStdioMCPClient 通过 stdio 连接
-func newStdioMCPClient() (*MCPClient, error) {
-	cmd := config.MCP.Stdio.ServerCmd
+// 初始化失败时关闭客户端并结束子进程，避免后台重连时每次失败都遗留一个子进程
+func newStdioMCPClient(cfg config.MCPServerConfig) (_ *MCPClient, err error) {
+	cmd := cfg.Stdio.ServerCmd
 	if cmd == "" {
 		cmd = "./bin/mcp-server"
 	}
-	client, err := mcpc.NewStdioMCPClient(cmd, nil, config.MCP.Stdio.ServerArgs...)
+	client, err := mcpc.NewStdioMCPClient(cmd, nil, cfg.Stdio.ServerArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("start stdio client: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			_ = client.Close()
+		}
+	}()
 
 	ctx, cancel := context.WithTimeout(context.Background(), constant.MCPClientInitTimeout)
 	defer cancel()
@@ -39,5 +45,5 @@ func newStdioMCPClient() (*MCPClient, error) {
 	if err != nil {
 		return nil, fmt.Errorf("list tools: %w", err)
 	}
-	return &MCPClient{Client: client, Tools: res.Tools}, nil
+	return &MCPClient{Client: client, tools: res.Tools}, nil
 }
@@ -0,0 +1,36 @@
+package mcp_client
+
+import (
+	"context"
+	"fmt"
+	"github.com/FantasyRL/go-mcp-demo/config"
+	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
+	"time"
+)
+
+// TimeoutError 工具调用超过超时时间仍未完成，已通知 server 取消
+type TimeoutError struct {
+	Tool    string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("tool %s timed out after %s", e.Tool, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// callTimeout 工具的调用超时：mcp.tool_timeouts > mcp.call_timeout > 默认值
+func callTimeout(name string) time.Duration {
+	if c := config.MCP; c != nil {
+		if t, ok := c.ToolTimeouts[name]; ok && t > 0 {
+			return t
+		}
+		if c.CallTimeout > 0 {
+			return c.CallTimeout
+		}
+	}
+	return constant.MCPDefaultCallTimeout
+}
@@ -16,18 +16,27 @@ type HTTPOpts struct {
 	HeartbeatInterval time.Duration // 建议 20~30s，降低中间件 idle 断开
 }
 
-// NewCoreServer 在此注册 tools/prompts/resources
+// NewCoreServer 在此注册 tools/resources
 func NewCoreServer(name, version string, toolSet *tool_set.ToolSet) *server.MCPServer {
-	s := server.NewMCPServer(
-		name,
-		version,
+	opts := []server.ServerOption{
 		server.WithRecovery(),
-		server.WithToolCapabilities(false),
-	)
+		server.WithToolCapabilities(true),
+	}
+	// 没有资源时不声明 resources 能力，客户端据此跳过 resources/list
+	if len(toolSet.Resources) != 0 || len(toolSet.ResourceTemplates) != 0 {
+		opts = append(opts, server.WithResourceCapabilities(false, true))
+	}
+	s := server.NewMCPServer(name, version, opts...)
 
 	for _, t := range toolSet.Tools {
 		s.AddTool(*t, toolSet.HandlerFunc[t.Name])
 	}
+	for _, r := range toolSet.Resources {
+		s.AddResource(*r, toolSet.ResourceHandlerFunc[r.URI])
+	}
+	for _, t := range toolSet.ResourceTemplates {
+		s.AddResourceTemplate(*t, toolSet.ResourceTemplateHandlerFunc[t.URITemplate.Raw()])
+	}
 
 	return s
 }
@@ -3,29 +3,37 @@ package base
 import (
 	"github.com/FantasyRL/go-mcp-demo/config"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/ai_provider"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/conversation_store"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
 	"github.com/FantasyRL/go-mcp-demo/pkg/base/registry/consul"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/stream_buffer"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_approval"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/tool_cache"
 	"github.com/FantasyRL/go-mcp-demo/pkg/constant"
 	"log"
 )
 
 func WithMCPClient() Option {
 	return func(clientSet *ClientSet) {
+		// 多个 MCP Server，工具名加 server 前缀
+		if len(config.MCP.Servers) > 0 {
+			setMCPPool(clientSet, config.MCP.Servers, true)
+			return
+		}
+		server := config.MCPServerConfig{
+			Name:      config.MCP.ServerName,
+			Transport: config.MCP.Transport,
+			Stdio:     config.MCP.Stdio,
+			HTTP:      config.MCP.HTTP,
+		}
+		if server.Name == "" {
+			server.Name = constant.MCPDefaultServerName
+		}
 		switch {
 		// stdio 启动
 		case config.MCP.Transport == constant.MCPTransportStdio:
-			mcpCli, err := mcp_client.NewMCPClient("")
-			if err != nil {
-				log.Fatalf("failed to create mcp client: %s", err)
-			}
-			clientSet.MCPCli = mcpCli
 		// 单点通信
 		case config.Registry.Provider == constant.RegistryProviderNone:
-			mcpCli, err := mcp_client.NewMCPClient(config.MCP.HTTP.BaseURL)
-			if err != nil {
-				log.Fatalf("failed to create mcp client: %s", err)
-			}
-			clientSet.MCPCli = mcpCli
 		// 服务发现模式
 		// todo: 做成真正的服务发现，目前仍然是单点通信
 		case config.Registry.Provider == constant.RegistryProviderConsul:
@@ -37,17 +45,22 @@ func WithMCPClient() Option {
 			if err != nil {
 				log.Fatalf("failed to resolve mcp url from consul: %s", err)
 			}
-			mcpCli, err := mcp_client.NewMCPClient(url)
-			if err != nil {
-				log.Fatalf("failed to create mcp client: %s", err)
-			}
+			server.HTTP.BaseURL = url
 			clientSet.RegistryResolver = resolver
-			clientSet.MCPCli = mcpCli
 		default:
 			log.Fatalf("unknown registry provider: %s,can't create MCP client", config.Registry.Provider)
 		}
+		setMCPPool(clientSet, []config.MCPServerConfig{server}, false)
+	}
+}
 
+func setMCPPool(clientSet *ClientSet, servers []config.MCPServerConfig, prefix bool) {
+	pool, err := mcp_client.NewPool(servers, prefix)
+	if err != nil {
+		log.Fatalf("failed to create mcp client: %s", err)
 	}
+	clientSet.MCPCli = pool
+	clientSet.cleanups = append(clientSet.cleanups, pool.Close)
 }
 
 func WithAiProviderClient() Option {
@@ -56,3 +69,57 @@ func WithAiProviderClient() Option {
 		clientSet.AiProviderCli = cli
 	}
 }
+
+func WithConversationStore() Option {
+	return func(clientSet *ClientSet) {
+		switch config.Conversation.Store {
+		case constant.ConversationStoreMemory, "":
+			clientSet.ConversationStore = conversation_store.NewMemoryStore()
+		case constant.ConversationStoreFile:
+			dir := config.Conversation.File.Dir
+			if dir == "" {
+				dir = constant.ConversationFileStoreDefaultDir
+			}
+			store, err := conversation_store.NewFileStore(dir)
+			if err != nil {
+				log.Fatalf("failed to create conversation store: %s", err)
+			}
+			clientSet.ConversationStore = store
+		default:
+			log.Fatalf("unknown conversation store: %s", config.Conversation.Store)
+		}
+	}
+}
+
+func WithStreamBuffer() Option {
+	return func(clientSet *ClientSet) {
+		ttl, reconnect := constant.SSEDefaultBufferTTL, constant.SSEDefaultReconnectTimeout
+		if config.SSE != nil && config.SSE.BufferTTL > 0 {
+			ttl = config.SSE.BufferTTL
+		}
+		if config.SSE != nil && config.SSE.ReconnectTimeout > 0 {
+			reconnect = config.SSE.ReconnectTimeout
+		}
+		clientSet.StreamBuffer = stream_buffer.NewStreamBuffer(ttl, reconnect)
+	}
+}
+
+func WithToolApprovals() Option {
+	return func(clientSet *ClientSet) {
+		timeout := constant.ToolApprovalDefaultTimeout
+		if config.ToolApproval != nil && config.ToolApproval.Timeout > 0 {
+			timeout = config.ToolApproval.Timeout
+		}
+		clientSet.ToolApprovals = tool_approval.NewRegistry(timeout)
+	}
+}
+
+// WithToolCache 配置启用 tool_cache 时创建工具结果缓存
+func WithToolCache() Option {
+	return func(clientSet *ClientSet) {
+		if config.ToolCache == nil || !config.ToolCache.Enable {
+			return
+		}
+		clientSet.ToolCache = tool_cache.NewCache(constant.ToolCacheMaxSessionEntries)
+	}
+}
@@ -0,0 +1,271 @@
+package stream_buffer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"sync"
+	"time"
+)
+
+// Event 一条已推送的流式事件
+type Event struct {
+	ID   int64  // 进程内单调递增，不因会话过期而重复
+	Name string // 事件名，见 constant.SSEEvent*
+	Data []byte // JSON
+}
+
+// StreamBuffer 按会话、轮次缓存流式对话推送过的事件，用于客户端断线后通过 Last-Event-ID 续传：
+// - 每个会话同时只允许一轮生成，事件 id 由整个 StreamBuffer 统一递增分配（会话过期后也不重复），因此 Last-Event-ID 即可定位到轮次
+// - 生成与客户端连接解耦；没有任何客户端订阅超过 reconnectTimeout 时取消生成
+// - 已结束的轮次在 ttl 后过期
+type StreamBuffer struct {
+	mu               sync.Mutex
+	sessions         map[string]*session
+	lastID           int64 // 最近分配的事件 id
+	ttl              time.Duration
+	reconnectTimeout time.Duration
+}
+
+type session struct {
+	turns []*Turn
+}
+
+func NewStreamBuffer(ttl, reconnectTimeout time.Duration) *StreamBuffer {
+	return &StreamBuffer{
+		sessions:         make(map[string]*session),
+		ttl:              ttl,
+		reconnectTimeout: reconnectTimeout,
+	}
+}
+
+// Start 为会话开始新的一轮，cancel 用于在无人订阅时取消生成；会话已有进行中的轮次时返回 errno.SessionBusy
+func (b *StreamBuffer) Start(sessionID string, cancel context.CancelFunc) (*Turn, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	s, ok := b.sessions[sessionID]
+	if !ok {
+		s = &session{}
+		b.sessions[sessionID] = s
+	}
+	for _, t := range s.turns {
+		if !t.Done() {
+			return nil, errno.SessionBusy
+		}
+	}
+	t := &Turn{
+		buffer: b,
+		cancel: cancel,
+		first:  b.lastID + 1,
+		notify: make(chan struct{}),
+	}
+	s.turns = append(s.turns, t)
+	return t, nil
+}
+
+// Find 返回包含 lastEventID 之后事件的轮次，找不到（如已过期）时返回 nil
+func (b *StreamBuffer) Find(sessionID string, lastEventID int64) *Turn {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	s, ok := b.sessions[sessionID]
+	if !ok {
+		return nil
+	}
+	for _, t := range s.turns {
+		if t.contains(lastEventID) {
+			return t
+		}
+	}
+	return nil
+}
+
+// expire 清理过期的轮次，调用方需持有 b.mu
+func (b *StreamBuffer) expire() {
+	now := time.Now()
+	for id, s := range b.sessions {
+		kept := s.turns[:0]
+		for _, t := range s.turns {
+			if end := t.finishedAt(); end.IsZero() || now.Sub(end) < b.ttl {
+				kept = append(kept, t)
+			}
+		}
+		s.turns = kept
+		if len(kept) == 0 {
+			delete(b.sessions, id)
+		}
+	}
+}
+
+// Running 会话中正在生成的一轮，没有时返回 nil
+func (b *StreamBuffer) Running(sessionID string) *Turn {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sessions[sessionID]
+	if !ok {
+		return nil
+	}
+	for _, t := range s.turns {
+		if !t.Done() {
+			return t
+		}
+	}
+	return nil
+}
+
+// Turn 一轮对话的事件缓存
+type Turn struct {
+	buffer *StreamBuffer
+	cancel context.CancelFunc
+	first  int64 // 本轮第一个事件的 id
+
+	mu          sync.Mutex
+	events      []Event
+	done        bool
+	endedAt     time.Time
+	notify      chan struct{} // 有新事件或结束时关闭并替换
+	subscribers int
+	idleTimer   *time.Timer
+}
+
+// Emit 缓存并广播一个事件，签名与 host 的推送函数一致；v 为 string/json.RawMessage 时原样作为 data
+func (t *Turn) Emit(event string, v any) error {
+	var data []byte
+	switch x := v.(type) {
+	case string:
+		data = []byte(x)
+	case json.RawMessage:
+		data = x
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = b
+	}
+
+	// 分配 id 与追加在同一临界区内完成，保证 events 按 id 递增，Subscribe 才能按 id 跳过已推送的事件
+	t.buffer.mu.Lock()
+	defer t.buffer.mu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buffer.lastID++
+	t.events = append(t.events, Event{ID: t.buffer.lastID, Name: event, Data: data})
+	t.broadcast()
+	return nil
+}
+
+// Finish 标记本轮结束，订阅者收完缓存事件后返回
+func (t *Turn) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return
+	}
+	t.done = true
+	t.endedAt = time.Now()
+	if t.idleTimer != nil {
+		t.idleTimer.Stop()
+	}
+	t.broadcast()
+}
+
+func (t *Turn) Done() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.done
+}
+
+// Cancel 主动取消本轮生成（如客户端发送 cancel），本轮随后正常结束
+func (t *Turn) Cancel() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// Subscribe 推送 id 大于 after 的事件：先回放缓存，再持续跟随直到本轮结束、ctx 结束或 fn 返回错误（通常是客户端断开）
+func (t *Turn) Subscribe(ctx context.Context, after int64, fn func(Event) error) error {
+	t.attach()
+	defer t.detach()
+
+	next := 0
+	for {
+		t.mu.Lock()
+		for next < len(t.events) && t.events[next].ID <= after {
+			next++
+		}
+		pending := t.events[next:]
+		done, notify := t.done, t.notify
+		t.mu.Unlock()
+
+		for _, e := range pending {
+			if err := fn(e); err != nil {
+				return err
+			}
+			next++
+		}
+		if len(pending) > 0 {
+			continue
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (t *Turn) attach() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subscribers++
+	if t.idleTimer != nil {
+		t.idleTimer.Stop()
+		t.idleTimer = nil
+	}
+}
+
+// detach 最后一个订阅者离开且本轮仍在生成时，等待 reconnectTimeout，仍无人重连则取消生成
+func (t *Turn) detach() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subscribers--
+	if t.subscribers > 0 || t.done || t.cancel == nil {
+		return
+	}
+	t.idleTimer = time.AfterFunc(t.buffer.reconnectTimeout, func() {
+		t.mu.Lock()
+		idle := t.subscribers == 0 && !t.done
+		t.mu.Unlock()
+		if idle {
+			t.cancel()
+		}
+	})
+}
+
+// contains 判断 lastEventID 之后的事件是否属于本轮
+func (t *Turn) contains(lastEventID int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := t.first - 1
+	if n := len(t.events); n > 0 {
+		last = t.events[n-1].ID
+	}
+	return lastEventID >= t.first-1 && lastEventID <= last
+}
+
+func (t *Turn) finishedAt() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.endedAt
+}
+
+// broadcast 唤醒所有等待中的订阅者，调用方需持有 t.mu
+func (t *Turn) broadcast() {
+	close(t.notify)
+	t.notify = make(chan struct{})
+}
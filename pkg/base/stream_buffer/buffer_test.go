@@ -0,0 +1,112 @@
+package stream_buffer
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+)
+
+func TestStreamBuffer(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test StreamBuffer", t, func() {
+		b := NewStreamBuffer(time.Minute, 20*time.Millisecond)
+		cancelled := make(chan struct{})
+		turn, err := b.Start("s", func() { close(cancelled) })
+		So(err, ShouldBeNil)
+
+		_, err = b.Start("s", func() {})
+		So(err, ShouldEqual, errno.SessionBusy)
+		So(b.Running("s"), ShouldEqual, turn)
+		So(b.Running("other"), ShouldBeNil)
+
+		So(turn.Emit("delta", map[string]any{"text": "a"}), ShouldBeNil)
+		So(turn.Emit("delta", map[string]any{"text": "b"}), ShouldBeNil)
+		So(b.Find("s", 1), ShouldEqual, turn)
+		So(b.Find("s", 5), ShouldBeNil)
+		So(b.Find("other", 1), ShouldBeNil)
+
+		// 从 id=1 之后回放，并跟随后续事件直到结束
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = turn.Emit("done", map[string]any{"reason": "completed"})
+			turn.Finish()
+		}()
+		var got []int64
+		So(turn.Subscribe(ctx, 1, func(e Event) error {
+			got = append(got, e.ID)
+			return nil
+		}), ShouldBeNil)
+		So(got, ShouldResemble, []int64{2, 3})
+
+		// 下一轮的 id 在会话内继续递增
+		next, err := b.Start("s", func() {})
+		So(err, ShouldBeNil)
+		So(next.Emit("delta", "x"), ShouldBeNil)
+		So(b.Find("s", 3), ShouldEqual, turn)
+		So(b.Find("s", 4), ShouldEqual, next)
+		So(b.Running("s"), ShouldEqual, next)
+
+		Convey("ids are not reused after the session expires", func() {
+			b := NewStreamBuffer(time.Millisecond, time.Minute)
+			old, err := b.Start("s", nil)
+			So(err, ShouldBeNil)
+			So(old.Emit("delta", "a"), ShouldBeNil)
+			So(old.Emit("done", "b"), ShouldBeNil)
+			old.Finish()
+			time.Sleep(5 * time.Millisecond)
+
+			// 旧轮次过期、会话被清理后重新开始，客户端带着旧的 Last-Event-ID 重连
+			turn, err := b.Start("s", nil)
+			So(err, ShouldBeNil)
+			So(turn.Emit("delta", "c"), ShouldBeNil)
+			So(turn.Emit("delta", "d"), ShouldBeNil)
+			So(turn.Emit("delta", "e"), ShouldBeNil)
+			So(b.Find("s", 1), ShouldBeNil)
+			So(b.Find("s", 2), ShouldEqual, turn)
+			So(b.Find("s", 3), ShouldEqual, turn)
+		})
+
+		Convey("concurrent emits keep id order", func() {
+			turn, err := b.Start("c", nil)
+			So(err, ShouldBeNil)
+			var wg sync.WaitGroup
+			for range 50 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = turn.Emit("delta", "x")
+				}()
+			}
+			wg.Wait()
+			turn.Finish()
+
+			var got []int64
+			So(turn.Subscribe(ctx, 0, func(e Event) error {
+				got = append(got, e.ID)
+				return nil
+			}), ShouldBeNil)
+			So(got, ShouldHaveLength, 50)
+			So(sort.SliceIsSorted(got, func(i, j int) bool { return got[i] < got[j] }), ShouldBeTrue)
+		})
+
+		Convey("cancel when nobody reconnects", func() {
+			idle, err := b.Start("idle", func() { close(cancelled) })
+			So(err, ShouldBeNil)
+			sctx, stop := context.WithCancel(ctx)
+			stop()
+			So(idle.Subscribe(sctx, 0, func(Event) error { return nil }), ShouldEqual, context.Canceled)
+			select {
+			case <-cancelled:
+			case <-time.After(time.Second):
+				t.Fatal("turn was not cancelled")
+			}
+		})
+	})
+}
@@ -0,0 +1,106 @@
+package tool_approval
+
+import (
+	"context"
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Request 一次等待审批的工具调用
+type Request struct {
+	ID        string    // 工具调用 id，与 tool_call 事件中的 id 对应
+	Name      string    // 工具名
+	Arguments string    // 调用参数（JSON）
+	CreatedAt time.Time // 开始等待的时间
+	ExpiresAt time.Time // 超时时间，届时未做出决定视为拒绝
+}
+
+// Registry 按会话登记等待用户审批的工具调用：对话循环在 Wait 处阻塞，
+// 客户端（WebSocket 控制消息等）通过 Resolve 做出决定；超时或对话被取消时视为拒绝
+type Registry struct {
+	mu      sync.Mutex
+	pending map[string]map[string]*pending // sessionID -> 工具调用 id -> 待审批
+	timeout time.Duration
+}
+
+type pending struct {
+	req      Request
+	decision chan bool
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{
+		pending: make(map[string]map[string]*pending),
+		timeout: timeout,
+	}
+}
+
+// Wait 登记工具调用并阻塞直到做出决定，返回是否批准；onPending 非空时在登记后调用（如通知客户端），
+// 此时已可以 Resolve
+func (r *Registry) Wait(ctx context.Context, sessionID string, req Request, onPending func(Request)) bool {
+	req.CreatedAt = time.Now()
+	req.ExpiresAt = req.CreatedAt.Add(r.timeout)
+	p := &pending{req: req, decision: make(chan bool, 1)}
+	r.mu.Lock()
+	calls, ok := r.pending[sessionID]
+	if !ok {
+		calls = make(map[string]*pending)
+		r.pending[sessionID] = calls
+	}
+	calls[req.ID] = p
+	r.mu.Unlock()
+
+	defer r.remove(sessionID, req.ID)
+	if onPending != nil {
+		onPending(req)
+	}
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	select {
+	case approved := <-p.decision:
+		return approved
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Resolve 对等待中的工具调用做出决定，没有对应的待审批调用时返回 errno.ApprovalNotExist
+func (r *Registry) Resolve(sessionID, callID string, approved bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.pending[sessionID][callID]
+	if !ok {
+		return errno.ApprovalNotExist
+	}
+	delete(r.pending[sessionID], callID)
+	p.decision <- approved
+	return nil
+}
+
+// Pending 会话中仍在等待审批的工具调用，按开始等待的时间排序
+func (r *Registry) Pending(sessionID string) []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Request, 0, len(r.pending[sessionID]))
+	for _, p := range r.pending[sessionID] {
+		out = append(out, p.req)
+	}
+	slices.SortFunc(out, func(a, b Request) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return out
+}
+
+func (r *Registry) remove(sessionID, callID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending[sessionID], callID)
+	if len(r.pending[sessionID]) == 0 {
+		delete(r.pending, sessionID)
+	}
+}
@@ -0,0 +1,68 @@
+package tool_approval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/pkg/errno"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test Registry", t, func() {
+		r := NewRegistry(50 * time.Millisecond)
+
+		Convey("approve and deny", func() {
+			result := make(chan bool)
+			go func() { result <- r.Wait(ctx, "s", Request{ID: "call_1", Name: "echo"}, nil) }()
+			So(waitPending(r, "s", 1), ShouldBeTrue)
+			So(r.Pending("s")[0].Name, ShouldEqual, "echo")
+			So(r.Resolve("s", "call_1", true), ShouldBeNil)
+			So(<-result, ShouldBeTrue)
+			So(r.Pending("s"), ShouldBeEmpty)
+
+			go func() { result <- r.Wait(ctx, "s", Request{ID: "call_2"}, nil) }()
+			So(waitPending(r, "s", 1), ShouldBeTrue)
+			So(r.Resolve("s", "call_2", false), ShouldBeNil)
+			So(<-result, ShouldBeFalse)
+		})
+
+		Convey("resolve from onPending", func() {
+			approved := r.Wait(ctx, "s", Request{ID: "call_5"}, func(req Request) {
+				So(req.ExpiresAt.After(req.CreatedAt), ShouldBeTrue)
+				So(r.Resolve("s", req.ID, true), ShouldBeNil)
+			})
+			So(approved, ShouldBeTrue)
+		})
+
+		Convey("unknown call", func() {
+			So(r.Resolve("s", "missing", true), ShouldEqual, errno.ApprovalNotExist)
+		})
+
+		Convey("deny on timeout", func() {
+			So(r.Wait(ctx, "s", Request{ID: "call_3"}, nil), ShouldBeFalse)
+			So(r.Resolve("s", "call_3", true), ShouldEqual, errno.ApprovalNotExist)
+		})
+
+		Convey("deny on cancel", func() {
+			cctx, cancel := context.WithCancel(ctx)
+			cancel()
+			So(r.Wait(cctx, "s", Request{ID: "call_4"}, nil), ShouldBeFalse)
+		})
+	})
+}
+
+// waitPending 等待会话中出现 n 个待审批的调用
+func waitPending(r *Registry, sessionID string, n int) bool {
+	for i := 0; i < 100; i++ {
+		if len(r.Pending(sessionID)) == n {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
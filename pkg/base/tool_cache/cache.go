@@ -0,0 +1,132 @@
+package tool_cache
+
+import (
+	"encoding/json"
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+	"sync"
+	"time"
+)
+
+// Cache 按会话缓存工具调用结果，键为工具名加规范化后的参数；
+// 会话中执行了写类型的工具后由调用方 Invalidate 清空该会话的缓存，会话删除后由调用方 Delete；
+// 新建会话缓存时顺带移除已没有未过期结果的会话
+type Cache struct {
+	mu         sync.Mutex
+	sessions   map[string]*session
+	maxEntries int    // 单个会话最多缓存的结果数
+	gen        uint64 // 每次清空或移除会话缓存时加一，新建的会话缓存使用当前值
+}
+
+type session struct {
+	entries map[string]entry
+	gen     uint64 // 清空前开始的调用结果不再写入，会话缓存被移除后重建也是如此
+}
+
+type entry struct {
+	result    *mcp_client.ToolResult
+	expiresAt time.Time
+}
+
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		sessions:   make(map[string]*session),
+		maxEntries: maxEntries,
+	}
+}
+
+// Key 缓存键：参数按 JSON 重新编码，对象的键有序，参数顺序不同的相同调用得到同一个键
+func Key(tool string, args any) string {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	return tool + "\x00" + string(b)
+}
+
+// Get 取未过期的缓存结果；未命中时在调用工具后将返回的 gen 传给 Set，
+// 调用期间会话缓存被清空时该结果不再写入
+func (c *Cache) Get(sessionID, key string) (*mcp_client.ToolResult, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.session(sessionID)
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, s.gen, false
+	}
+	return e.result, s.gen, true
+}
+
+// Set 缓存结果 ttl 时长；会话缓存已满时先清理过期的结果，仍然满时淘汰最早过期的结果
+func (c *Cache) Set(sessionID, key string, result *mcp_client.ToolResult, ttl time.Duration, gen uint64) {
+	if key == "" || ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.session(sessionID)
+	if s.gen != gen {
+		return
+	}
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= c.maxEntries {
+		now := time.Now()
+		var oldest string
+		for k, e := range s.entries {
+			if now.After(e.expiresAt) {
+				delete(s.entries, k)
+			} else if oldest == "" || e.expiresAt.Before(s.entries[oldest].expiresAt) {
+				oldest = k
+			}
+		}
+		if len(s.entries) >= c.maxEntries {
+			delete(s.entries, oldest)
+		}
+	}
+	s.entries[key] = entry{result: result, expiresAt: time.Now().Add(ttl)}
+}
+
+// Invalidate 清空会话的全部缓存，返回清空后的 gen，之后开始的调用结果可以此写入
+func (c *Cache) Invalidate(sessionID string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	if s, ok := c.sessions[sessionID]; ok {
+		clear(s.entries)
+		s.gen = c.gen
+	}
+	return c.gen
+}
+
+// Delete 移除会话的全部缓存，会话被删除或清空时调用
+func (c *Cache) Delete(sessionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	delete(c.sessions, sessionID)
+}
+
+func (c *Cache) session(sessionID string) *session {
+	s, ok := c.sessions[sessionID]
+	if !ok {
+		c.prune()
+		s = &session{entries: make(map[string]entry), gen: c.gen}
+		c.sessions[sessionID] = s
+	}
+	return s
+}
+
+// prune 移除没有未过期结果的会话
+func (c *Cache) prune() {
+	now := time.Now()
+	for id, s := range c.sessions {
+		expired := true
+		for _, e := range s.entries {
+			if !now.After(e.expiresAt) {
+				expired = false
+				break
+			}
+		}
+		if expired {
+			delete(c.sessions, id)
+		}
+	}
+}
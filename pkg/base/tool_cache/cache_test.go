@@ -0,0 +1,91 @@
+package tool_cache
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/FantasyRL/go-mcp-demo/pkg/base/mcp_client"
+)
+
+func TestCache(t *testing.T) {
+	Convey("Test Cache", t, func() {
+		c := NewCache(2)
+		res := mcp_client.TextResult("tree")
+
+		Convey("key", func() {
+			a := Key("fs_tree", map[string]any{"path": ".", "depth": 2})
+			b := Key("fs_tree", map[string]any{"depth": 2, "path": "."})
+			So(a, ShouldEqual, b)
+			So(Key("fs_cat", map[string]any{"path": "."}), ShouldNotEqual, Key("fs_tree", map[string]any{"path": "."}))
+		})
+
+		Convey("get, expire and invalidate", func() {
+			key := Key("fs_tree", map[string]any{"path": "."})
+			_, gen, ok := c.Get("s", key)
+			So(ok, ShouldBeFalse)
+			c.Set("s", key, res, 50*time.Millisecond, gen)
+			got, _, ok := c.Get("s", key)
+			So(ok, ShouldBeTrue)
+			So(got, ShouldEqual, res)
+			_, _, ok = c.Get("other", key)
+			So(ok, ShouldBeFalse)
+
+			time.Sleep(60 * time.Millisecond)
+			_, gen, ok = c.Get("s", key)
+			So(ok, ShouldBeFalse)
+
+			c.Set("s", key, res, time.Minute, gen)
+			c.Invalidate("s")
+			_, gen, ok = c.Get("s", key)
+			So(ok, ShouldBeFalse)
+
+			// 清空前开始的调用，结果不再写入
+			c.Invalidate("s")
+			c.Set("s", key, res, time.Minute, gen)
+			_, _, ok = c.Get("s", key)
+			So(ok, ShouldBeFalse)
+
+			// 以清空后返回的 gen 写入
+			gen = c.Invalidate("s")
+			c.Set("s", key, res, time.Minute, gen)
+			_, _, ok = c.Get("s", key)
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("evict earliest expiring when full", func() {
+			c.Set("s", "a", res, time.Minute, 0)
+			c.Set("s", "b", res, time.Second, 0)
+			c.Set("s", "c", res, time.Minute, 0)
+			_, _, ok := c.Get("s", "b")
+			So(ok, ShouldBeFalse)
+			_, _, ok = c.Get("s", "a")
+			So(ok, ShouldBeTrue)
+			_, _, ok = c.Get("s", "c")
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("delete and prune sessions", func() {
+			key := Key("fs_tree", map[string]any{"path": "."})
+			_, gen, _ := c.Get("a", key)
+			c.Set("a", key, res, time.Minute, gen)
+			_, gen, _ = c.Get("b", key)
+			c.Set("b", key, res, 50*time.Millisecond, gen)
+			So(c.sessions, ShouldHaveLength, 2)
+
+			// 删除前开始的调用，结果不再写入
+			_, gen, _ = c.Get("a", "other")
+			c.Delete("a")
+			So(c.sessions, ShouldHaveLength, 1)
+			c.Set("a", "other", res, time.Minute, gen)
+			_, _, ok := c.Get("a", "other")
+			So(ok, ShouldBeFalse)
+
+			// 新建会话缓存时移除结果均已过期的会话
+			time.Sleep(60 * time.Millisecond)
+			c.Get("c", key)
+			So(c.sessions, ShouldNotContainKey, "b")
+		})
+	})
+}
@@ -16,9 +16,17 @@ type ToolSet struct {
 	Tools []*mcp.Tool
 	// map[t.Name]HandlerFunc
 	HandlerFunc map[string]server.ToolHandlerFunc
+	// resources
+	Resources []*mcp.Resource
+	// map[r.URI]ResourceHandlerFunc
+	ResourceHandlerFunc map[string]server.ResourceHandlerFunc
+	// resource templates
+	ResourceTemplates []*mcp.ResourceTemplate
+	// map[t.URITemplate.Raw()]ResourceTemplateHandlerFunc
+	ResourceTemplateHandlerFunc map[string]server.ResourceTemplateHandlerFunc
 }
 
-// Option 定义了一个参数为toolSet的函数，具体实现为在函数内对toolSet进行append
+// Option 定义了一个参数为toolSet的函数，具体实现为在函数内对toolSet进行append（工具、资源或资源模板）
 type Option func(toolSet *ToolSet)
 
 func NewToolSet(opt ...Option) *ToolSet {
@@ -26,6 +34,8 @@ func NewToolSet(opt ...Option) *ToolSet {
 		var options []Option
 		instance = new(ToolSet)
 		instance.HandlerFunc = make(map[string]server.ToolHandlerFunc)
+		instance.ResourceHandlerFunc = make(map[string]server.ResourceHandlerFunc)
+		instance.ResourceTemplateHandlerFunc = make(map[string]server.ResourceTemplateHandlerFunc)
 		options = append(options, opt...)
 		for _, opt := range options {
 			opt(instance)
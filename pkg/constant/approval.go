@@ -0,0 +1,11 @@
+package constant
+
+import "time"
+
+const (
+	ToolPolicyAuto            = "auto"             // 直接执行
+	ToolPolicyRequireApproval = "require_approval" // 执行前等待用户批准
+	ToolPolicyDeny            = "deny"             // 禁止调用
+
+	ToolApprovalDefaultTimeout = 2 * time.Minute // 工具调用等待审批的默认时长，超时视为拒绝
+)
@@ -0,0 +1,10 @@
+package constant
+
+const (
+	ConversationStoreMemory = "memory" // 对话历史保存在内存
+	ConversationStoreFile   = "file"   // 对话历史保存为本地文件
+
+	ConversationFileStoreDefaultDir = "./data/conversations" // 文件存储默认目录
+
+	MessageStatusCancelled = "cancelled" // 客户端断开等原因导致该轮对话被取消
+)
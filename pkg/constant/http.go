@@ -0,0 +1,6 @@
+package constant
+
+const (
+	HeaderSessionID   = "X-Session-Id"  // 返回本次对话实际使用的会话ID
+	HeaderLastEventID = "Last-Event-ID" // SSE 断线重连时客户端携带的最后事件ID
+)
@@ -3,13 +3,28 @@ package constant
 import "time"
 
 const (
-	MCPTransportStdio          = "stdio"          // MCP基于标准输入输出连接
-	MCPTransportSSE            = "sse"            // MCP基于SSE连接
-	MCPTransportHTTP           = "http"           // MCP基于http连接
-	MCPClientInitTimeout       = 5 * time.Second  // MCP客户端初始化超时时间
-	MCPDefaultCallTimeout      = 30 * time.Second // MCP调用默认超时时间
-	MCPServerHeartbeatInterval = 25 * time.Second // MCP服务器心跳间隔
+	MCPTransportStdio          = "stdio"                   // MCP基于标准输入输出连接
+	MCPTransportSSE            = "sse"                     // MCP基于SSE连接
+	MCPTransportHTTP           = "http"                    // MCP基于http连接
+	MCPClientInitTimeout       = 5 * time.Second           // MCP客户端初始化超时时间
+	MCPDefaultCallTimeout      = 30 * time.Second          // MCP调用默认超时时间
+	MCPServerHeartbeatInterval = 25 * time.Second          // MCP服务器心跳间隔
+	MCPReconnectMinBackoff     = 500 * time.Millisecond    // MCP断线重连的初始退避间隔
+	MCPReconnectMaxBackoff     = 30 * time.Second          // MCP断线重连的最大退避间隔
+	MCPNotificationCancelled   = "notifications/cancelled" // 通知MCP服务器取消请求
+	MCPNotificationProgress    = "notifications/progress"  // MCP工具调用进度通知
+	MCPToolNameSeparator       = "__"                      // 多个MCP服务器时工具名前缀与原名之间的分隔符
+	MCPDefaultServerName       = "default"                 // 未配置 server_name 时单个MCP服务器的名称
+	MCPResourceMaxBytes        = 1 << 20                   // MCP资源单次读取的最大字节数
+	MCPLogResourceTailLines    = 200                       // log:// 资源返回的最近日志行数
 
 	AiProviderModeLocal  = "local"  // 本地模型
 	AiProviderModeRemote = "remote" // 远程模型
+
+	AiProviderDefaultToolRoundLimit  = 10 // 单次对话默认最多工具调用轮数，避免死循环
+	AiProviderDefaultToolParallelism = 4  // 同一轮中默认最多同时执行的工具调用数
+
+	ContextDefaultMaxToolOutputTokens = 1024 // 超过上下文预算时单条工具输出默认最多保留的 token 数
+	ContextDefaultKeepTurns           = 2    // 摘要时默认原样保留的最近对话轮数
+	ContextImageTokens                = 768  // 估算上下文时每张图片计入的 token 数
 )
@@ -1,9 +1,17 @@
 package constant
 
+import "time"
+
 const (
-	SSEEventDelta         = "delta"           // 模型内容增量
-	SSEEventDone          = "done"            // 流结束事件
-	SSEEventStartToolCall = "start_tool_call" // 开始工具调用
-	SSEEventToolCall      = "tool_call"       // 工具调用
-	SSEEventToolResult    = "tool_result"     // 工具调用结果
+	SSEEventDelta            = "delta"             // 模型内容增量
+	SSEEventDone             = "done"              // 流结束事件
+	SSEEventStartToolCall    = "start_tool_call"   // 开始工具调用
+	SSEEventToolCall         = "tool_call"         // 工具调用
+	SSEEventToolResult       = "tool_result"       // 工具调用结果
+	SSEEventToolProgress     = "tool_progress"     // 工具调用进度
+	SSEEventApprovalRequired = "approval_required" // 工具调用等待用户审批
+	SSEEventError            = "error"             // 对话出错，流随即结束
+
+	SSEDefaultBufferTTL        = 5 * time.Minute  // 已结束轮次的事件默认缓存时长
+	SSEDefaultReconnectTimeout = 30 * time.Second // 客户端断开后默认等待重连的时长，超时取消生成
 )
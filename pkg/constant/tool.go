@@ -0,0 +1,18 @@
+package constant
+
+import "time"
+
+// 工具调用的结果分类，用于交给模型的结果、tool_result 事件、会话记录与监控指标
+const (
+	ToolOutcomeSuccess        = "success"         // 执行成功
+	ToolOutcomeToolError      = "tool_error"      // 工具执行失败（工具返回 isError、参数错误、工具不存在等）
+	ToolOutcomeTransportError = "transport_error" // 与 MCP Server 的连接出错或 server 不可用
+	ToolOutcomeTimeout        = "timeout"         // 超过调用超时，已取消
+	ToolOutcomeDenied         = "denied"          // 不在允许范围、被策略禁止或审批被拒绝
+	ToolOutcomeCancelled      = "cancelled"       // 客户端断开等原因导致调用被取消
+)
+
+const (
+	ToolCacheDefaultTTL        = time.Minute // 工具结果默认缓存时长
+	ToolCacheMaxSessionEntries = 256         // 单个会话最多缓存的工具结果数
+)
@@ -0,0 +1,18 @@
+package constant
+
+import "time"
+
+const (
+	WSFrameUserMessage = "user_message" // 客户端：发送用户消息
+	WSFrameCancel      = "cancel"       // 客户端：取消正在生成的回复
+	WSFrameApproveTool = "approve_tool" // 客户端：批准工具调用
+	WSFrameDenyTool    = "deny_tool"    // 客户端：拒绝工具调用
+
+	WSEventSession = "session" // 服务端：连接建立后返回实际使用的会话ID
+
+	WSMaxQueuedMessages = 8        // 正在生成时最多排队的用户消息数
+	WSMaxMessageSize    = 16 << 20 // 客户端单条消息的最大字节数（含附件）
+
+	WSPingInterval = 30 * time.Second // 服务端发送 ping 的间隔
+	WSReadTimeout  = 75 * time.Second // 超过该时长未收到客户端的任何帧（含 pong）即断开
+)
@@ -2,4 +2,13 @@ package errno
 
 var (
 	OllamaInternalStopStream = NewErrNo(OllamaInternalStopStreamCode, "服务内部通知ollama停止流")
+
+	SessionForbidden = NewErrNo(AuthInvalidCode, "无权访问该会话") // 会话属于其他用户
+	SessionNotExist  = NewErrNo(BizNotExist, "会话不存在")
+	SessionBusy      = NewErrNo(BizLimitCode, "会话正在生成回复，请稍后再试") // 同一会话同时只允许一轮生成
+
+	ApprovalNotExist = NewErrNo(BizNotExist, "没有等待审批的该工具调用")  // 已做出决定、已超时或 id 不存在
+	ToolDenied       = NewErrNo(BizToolDeniedCode, "工具调用被拒绝") // 不在允许范围、被策略禁止或审批被拒绝
+
+	ContextTooLong = NewErrNo(ParamTooLongCode, "本轮对话超出模型的上下文预算，请缩短消息或减少附件") // 截断工具输出后最近一轮仍超出预算
 )
@@ -56,6 +56,7 @@ const (
 	BizFileUploadErrorCode        = 40006 // 文件上传错误(service 层)
 	BizJwchCookieExceptionCode    = 40007 // jwch cookie异常
 	BizJwchEvaluationNotFoundCode = 40008 // jwch 未进行评测
+	BizToolDeniedCode             = 40009 // 工具调用被拒绝
 
 	InternalServiceErrorCode   = 50001 // 未知服务错误
 	InternalDatabaseErrorCode  = 50002 // 数据库错误
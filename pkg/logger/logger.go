@@ -129,6 +129,15 @@ func (l *controlLogger) updateLogger(service string) {
 	l.logger.Logger = BuildLogger(cfg, l.addZapOptions(service)...)
 }
 
+// FilePath 返回 service 在 date 当天的日志文件路径，与 Init 之后日志写入的位置一致
+func FilePath(service string, date time.Time) (string, error) {
+	pwd, err := getCurrentDirectory()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(constants.LogFilePathTemplate, pwd, constants.LogFilePath, date.Format("2006-01-02"), service), nil
+}
+
 func (l *controlLogger) addZapOptions(serviceName string) []zap.Option {
 	var opts []zap.Option
 	if len(l.hooks) != 0 {
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+// UnknownTool 不在工具列表中的工具名（如模型编造的）统一使用的标签值
+const UnknownTool = "unknown"
+
+var (
+	// ToolCalls 工具调用次数，按工具名与结果分类（constant.ToolOutcome*）统计
+	ToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_tool_calls_total",
+		Help: "Number of MCP tool calls by tool and outcome.",
+	}, []string{"tool", "outcome"})
+
+	// ToolCallDuration 工具调用耗时（含审批等待），按工具名与结果分类统计，不含命中缓存的调用
+	ToolCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_tool_call_duration_seconds",
+		Help:    "Duration of MCP tool calls by tool and outcome.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12), // 50ms ~ 约 100s
+	}, []string{"tool", "outcome"})
+)
+
+func init() {
+	prometheus.MustRegister(ToolCalls, ToolCallDuration)
+}
+
+// ObserveToolCall 记录一次工具调用，cached 表示结果来自缓存，其耗时不计入 ToolCallDuration
+func ObserveToolCall(tool, outcome string, cached bool, d time.Duration) {
+	ToolCalls.WithLabelValues(tool, outcome).Inc()
+	if !cached {
+		ToolCallDuration.WithLabelValues(tool, outcome).Observe(d.Seconds())
+	}
+}
+
+// Handler Prometheus 抓取接口
+func Handler() http.Handler {
+	return promhttp.Handler()
+}